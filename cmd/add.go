@@ -5,12 +5,9 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"strconv"
-	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/dates"
 	"github.com/webbben/task/internal/tasks"
 	"github.com/webbben/task/internal/types"
 )
@@ -19,6 +16,8 @@ var (
 	description string
 	category    string
 	dueDate     string
+	tags        []string
+	estimate    string
 )
 
 // addCmd represents the add command
@@ -38,19 +37,47 @@ task add "work on task app" -d "finish the usage examples" -D 12/5/2024
 # Add a task that is due in 2 days (d=days, w=weeks, m=months, y=years)
 task add "get this done next week" -D 2d
 
-the "title" argument is required, but all other arguments are optional. If no due date is provided, it defaults to today.`,
+# Due dates can also be written out, e.g. tomorrow, eow, "next fri", "in 3 days", "nov 3", 2026-11-03, "fri 15:00"
+task add "send the report" -D "end of month"
+
+# Add a task with tags
+task add "fix login bug" -T bug,frontend
+
+# Add a task with an estimate, as time or story points
+task add "write migration" -e 2h
+task add "new settings page" -e 3pt
+
+the "title" argument is required, but all other arguments are optional. If no due date is provided, it defaults to today
+(or the "default_due" config setting).`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		title := args[0]
 
 		// Parse the due date
-		due, err := parseDueDate(dueDate)
+		due, err := dates.ParseDue(dueDate)
 		if err != nil {
 			fmt.Println("Error parsing due date:", err)
 			return
 		}
+		if due.Ambiguity != "" {
+			fmt.Println("Note:", due.Ambiguity)
+		}
 
-		t, err := tasks.AddTask(title, description, category, due)
+		est, err := tasks.ParseEstimate(estimate)
+		if err != nil {
+			fmt.Println("Error parsing estimate:", err)
+			return
+		}
+
+		task := types.Task{
+			Title:       title,
+			Description: description,
+			Category:    category,
+			Tags:        tags,
+			Estimate:    est,
+		}
+		task.SetDue(due.Time, due.HasTime)
+		t, err := tasks.CreateTask(task)
 		if err != nil {
 			fmt.Println("Error adding task:", err)
 			return
@@ -63,90 +90,8 @@ func init() {
 	addCmd.Flags().StringVarP(&description, "description", "d", "", "a description of the task")
 	addCmd.Flags().StringVarP(&category, "category", "c", "", "a category for the task")
 	addCmd.Flags().StringVarP(&dueDate, "due-date", "D", "", "the due date for the task")
+	addCmd.Flags().StringSliceVarP(&tags, "tags", "T", nil, "comma separated tags for the task")
+	addCmd.Flags().StringVarP(&estimate, "estimate", "e", "", "how much work the task is expected to take, e.g. 2h or 3pt")
 
 	rootCmd.AddCommand(addCmd)
 }
-
-// parseDueDate parses the due date string and returns a time.Time
-func parseDueDate(dueDate string) (time.Time, error) {
-	if dueDate == "" {
-		return time.Now(), nil
-	}
-	// check if the due date is a precise date (i.e. uses a slash delimiter)
-	if strings.Contains(dueDate, "/") {
-		// if year isn't defined, default to the current year and add it on
-		parts := strings.Split(dueDate, "/")
-		if len(parts) < 2 || len(parts) > 3 {
-			return time.Time{}, fmt.Errorf("invalid date format")
-		}
-		if len(parts) == 2 {
-			dueDate += "/" + time.Now().Format("2006")
-		}
-		return time.Parse("1/2/2006", dueDate)
-	}
-
-	// check if it's a weekday
-	if len(dueDate) >= 3 {
-		isWeekday, weekdayDueDate := parseWeekDay(dueDate)
-		if isWeekday {
-			return weekdayDueDate, nil
-		}
-	}
-
-	// if its not a full date or weekday, then it should be a relative date
-	// check if the format is correct (number followed by a letter)
-	if len(dueDate) < 2 {
-		return time.Time{}, fmt.Errorf("invalid date format")
-	}
-	// get the number and the unit and calculate the due date
-	number, err := strconv.Atoi(dueDate[:len(dueDate)-1])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid format for relative due date")
-	}
-	unit := dueDate[len(dueDate)-1:]
-	today := time.Now()
-	switch unit {
-	case "d":
-		return today.AddDate(0, 0, number), nil
-	case "w":
-		return today.AddDate(0, 0, number*7), nil
-	case "m":
-		return today.AddDate(0, number, 0), nil
-	case "y":
-		return today.AddDate(number, 0, 0), nil
-	default:
-		return time.Time{}, fmt.Errorf("invalid unit for relative due date")
-	}
-}
-
-func parseWeekDay(s string) (bool, time.Time) {
-	if len(s) < 3 {
-		return false, time.Time{}
-	}
-	weekdays := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
-	match := ""
-	s = strings.ToLower(s)
-	for _, day := range weekdays {
-		if strings.HasPrefix(s, day) {
-			match = day
-			break
-		}
-	}
-	if match == "" {
-		return false, time.Time{}
-	}
-
-	// find the next date for the given day of the week
-	// start at tomorrow, so if entered day of week is the same as today, it goes to next week instead of today
-	cur := time.Now().AddDate(0, 0, 1)
-	i := 0
-	for strings.ToLower(cur.Format("Mon")) != match {
-		cur = cur.AddDate(0, 0, 1)
-		i++
-		if i > 7 {
-			log.Println("failed to find next weekday?")
-			break
-		}
-	}
-	return true, cur
-}
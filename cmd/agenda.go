@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+var agendaDays int
+
+// agendaCmd represents the agenda command
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "show the tasks due over the next few days, grouped by day",
+	Long: `Show the tasks due over the next few days under a header for each day, after the overdue tasks.
+
+Tasks completed today are shown under today, and snoozed tasks are shown on the day they wake up.
+Tasks don't repeat, so each one is only shown on its own dates; there are no recurring occurrences to fill in.
+
+Example:
+
+task agenda --days 14`,
+	Args:        cobra.NoArgs,
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if agendaDays < 1 {
+			cmd.PrintErrln("--days must be at least 1")
+			return
+		}
+		all, err := loadTasks()
+		if err != nil {
+			cmd.PrintErrln("Error loading tasks:", err)
+			return
+		}
+
+		overdue, days := tasks.Agenda(all, time.Now(), agendaDays)
+		header := color.New(color.Bold)
+		if len(overdue) > 0 {
+			config.ColorOrNone(config.Get().Theme.Late).Add(color.Bold).Println("Overdue")
+			for _, t := range overdue {
+				printAgendaLine(formatAgendaDue(t), t)
+			}
+			fmt.Println()
+		}
+		for i, day := range days {
+			header.Println(agendaDayLabel(day.Date, i))
+			if len(day.Entries) == 0 {
+				fmt.Println(color.New(color.FgHiBlack).Sprint("  nothing due"))
+			}
+			for _, e := range day.Entries {
+				switch e.Kind {
+				case tasks.AgendaDone:
+					printAgendaLine("done", e.Task)
+				case tasks.AgendaWakes:
+					printAgendaLine("wakes up", e.Task)
+				default:
+					printAgendaLine(formatAgendaDue(e.Task), e.Task)
+				}
+			}
+			if i < len(days)-1 {
+				fmt.Println()
+			}
+		}
+	},
+}
+
+// agendaDayLabel names the i-th day of the agenda: Today, Tomorrow, then the weekday and date
+func agendaDayLabel(day time.Time, i int) string {
+	switch i {
+	case 0:
+		return "Today, " + day.Format("Mon Jan 2")
+	case 1:
+		return "Tomorrow, " + day.Format("Mon Jan 2")
+	}
+	return day.Format("Monday, Jan 2")
+}
+
+// formatAgendaDue shows the due time for tasks due at a time of day, or the due date for overdue ones, colored like the due column
+func formatAgendaDue(t types.Task) string {
+	if !t.DueHasTime && !t.Due().Before(util.RoundDateDown(time.Now())) {
+		return ""
+	}
+	return tasks.FormatDue(t)
+}
+
+// printAgendaLine prints a task under a day header, with when (e.g. its due time) in the first column
+func printAgendaLine(when string, t types.Task) {
+	// PadRight pads by the visible width, since when may be colored
+	line := fmt.Sprintf("  %s %-8s  %s", util.PadRight(when, 11), util.Cut(t.ID, 8), t.Title)
+	if t.Category != "" {
+		line += color.New(color.FgHiBlack).Sprintf("  [%s]", t.Category)
+	}
+	fmt.Println(line)
+}
+
+func init() {
+	agendaCmd.Flags().IntVarP(&agendaDays, "days", "n", 7, "number of days to show, starting today")
+	rootCmd.AddCommand(agendaCmd)
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+var attachmentName string
+
+// attachCmd represents the attach command
+var attachCmd = &cobra.Command{
+	Use:   "attach <task> <path|url>",
+	Short: "attach a file or link to a task",
+	Long: `Attach a file or a URL to a task. Files are copied into the attachment store, so the original can be moved or deleted.
+The task can be given by its ID or by words from its title.
+
+Example usage:
+
+# attach a PR link
+task attach 9bf4 https://github.com/webbben/task/pull/12
+
+# attach a log excerpt with a friendlier name
+task attach 9bf4 ./build.log -n "failing build"
+
+# list and open attachments
+task view 9bf4
+task open 9bf4 2`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[1]
+		// make sure the task exists before copying anything into the store
+		taskID, err := resolveTaskID(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		a, err := newAttachment(target)
+		if err != nil {
+			cmd.PrintErrln("Error attaching:", err)
+			return
+		}
+		if attachmentName != "" {
+			a.Name = attachmentName
+		}
+		if _, err := tasks.AddAttachment(taskID, a); err != nil {
+			cmd.PrintErrln("Error attaching:", err)
+			return
+		}
+		fmt.Printf("Attached %s to task %s\n", a.Name, taskID)
+	},
+}
+
+// newAttachment stores the given file, or makes a link attachment if it isn't a file but looks like a URL
+func newAttachment(target string) (types.Attachment, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		if isURL(target) {
+			return types.Attachment{Name: target, URL: target}, nil
+		}
+		return types.Attachment{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return types.Attachment{}, fmt.Errorf("%s is not a regular file", target)
+	}
+	hash, size, err := storage.StoreBlob(target)
+	if err != nil {
+		return types.Attachment{}, err
+	}
+	name := filepath.Base(target)
+	return types.Attachment{Name: name, FileName: name, Hash: hash, Size: size}, nil
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	// e.g. mailto: links have no host
+	return u.Host != "" || u.Opaque != ""
+}
+
+var attachRmCmd = &cobra.Command{
+	Use:   "rm <task> <n>",
+	Short: "remove an attachment from a task",
+	Long: `Remove the nth attachment from a task, as numbered in "task view".
+
+Stored files are only deleted from the attachment store by "task attach gc".`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			cmd.PrintErrln("invalid attachment number:", args[1])
+			return
+		}
+		taskID, err := resolveTaskIDToChange(args[0], "Remove the attachment from")
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		a, err := tasks.RemoveAttachment(taskID, n)
+		if err != nil {
+			cmd.PrintErrln("Error removing attachment:", err)
+			return
+		}
+		fmt.Printf("Removed %s from task %s\n", a.Name, taskID)
+	},
+}
+
+var attachGcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "delete stored files that are no longer attached to any task",
+	Long: `Delete files from the attachment store that aren't attached to any task, active or completed, in any workspace.
+
+Files that were stored in the last hour are kept, in case a task is still being saved.`,
+	Args:        cobra.NoArgs,
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		used, err := usedBlobs()
+		if err != nil {
+			cmd.PrintErrln("Error reading attachments:", err)
+			return
+		}
+		removed, freed, err := storage.RemoveUnusedBlobs(used)
+		if err != nil {
+			cmd.PrintErrln("Error cleaning up attachments:", err)
+		}
+		fmt.Printf("Removed %d unused file(s), freeing %s\n", removed, util.FormatSize(freed))
+	},
+}
+
+// usedBlobs collects the stored files used by every workspace, since they share the attachment store
+func usedBlobs() (map[string]bool, error) {
+	names, err := storage.ListWorkspaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	used := make(map[string]bool)
+	for _, name := range names {
+		err := storage.WithWorkspace(name, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}, func() error {
+			return tasks.UsedBlobs(used)
+		})
+		if err != nil {
+			// skipping a workspace could delete files it still uses
+			return nil, errors.Join(fmt.Errorf("workspace %s", name), err)
+		}
+	}
+	return used, nil
+}
+
+func init() {
+	attachCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completions.TaskIDCompletionFn(true)(cmd, args, toComplete)
+		}
+		// complete file paths for the attachment itself
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	attachRmCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	attachCmd.Flags().StringVarP(&attachmentName, "name", "n", "", "name to show for the attachment (defaults to the file name or URL)")
+	attachCmd.AddCommand(attachRmCmd, attachGcCmd)
+	rootCmd.AddCommand(attachCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/ui/board"
+)
+
+// boardCmd represents the board command
+var boardCmd = &cobra.Command{
+	Use:   "board",
+	Short: "Show tasks as a kanban board by status",
+	Long: `Launch a TUI kanban board with a column for each status: waiting, in progress, and tasks completed today.
+
+Move between cards with the arrow keys (or h/j/k/l), and move the selected card to another column with
+shift+left/right (or H/L). Moving a card into the complete column completes and archives the task.
+
+Example:
+
+task board`,
+	Args: cobra.NoArgs,
+	// the board opens the database itself, only while reading or writing, so it doesn't lock out other commands
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := board.Run(resolveWorkspace()); err != nil {
+			cmd.PrintErrln(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(boardCmd)
+}
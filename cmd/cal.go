@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/calendar"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+)
+
+// layouts accepted for the month argument of "task cal"
+var monthLayouts = []string{"Jan", "January", "Jan 2006", "January 2006", "2006-01", "1/2006"}
+
+// calCmd represents the cal command
+var calCmd = &cobra.Command{
+	Use:   "cal [month]",
+	Short: "show a month calendar with the number of tasks due each day",
+	Long: `Show a month calendar with the number of unfinished tasks due each day, in parentheses.
+Days off (weekends and holidays) are dimmed, and the holidays of the month are listed below it.
+
+Working days are set with the work_days config value, and holidays with holidays or "task cal import".
+Business day due dates like 3bd skip the days off.
+
+Example usage:
+
+# this month
+task cal
+
+# another month
+task cal nov
+task cal 2027-01`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		now := time.Now()
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		if len(args) == 1 {
+			var err error
+			month, err = parseMonth(args[0], now)
+			if err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+		}
+
+		all, err := tasks.GetAllTasks()
+		if err != nil {
+			cmd.PrintErrln("Error loading tasks:", err)
+			return
+		}
+		printMonth(month, dueCounts(all), calendar.FromConfig(), config.Get().WeekStartDay())
+	},
+}
+
+var calImportCmd = &cobra.Command{
+	Use:   "import <file.ics>",
+	Short: "add the events of an iCalendar file as holidays",
+	Long: `Add every event in an iCalendar (.ics) file to the holidays config value, so those days aren't counted as working days.
+Public holiday calendars can be downloaded as .ics files from most calendar apps.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := os.Open(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		defer f.Close()
+		holidays, err := calendar.ParseICS(f)
+		if err != nil {
+			cmd.PrintErrln("Error reading calendar:", err)
+			return
+		}
+
+		c, err := config.LoadFile()
+		if err != nil {
+			cmd.PrintErrln("Error loading config:", err)
+			return
+		}
+		if c.Holidays == nil {
+			c.Holidays = make(map[string]string)
+		}
+		added := 0
+		for _, h := range holidays {
+			day := h.Date.Format(time.DateOnly)
+			if _, ok := c.Holidays[day]; !ok {
+				added++
+			}
+			// the holidays setting is a comma separated list, so names can't have commas
+			c.Holidays[day] = strings.TrimSpace(strings.ReplaceAll(h.Name, ",", ""))
+		}
+		if err := config.Save(c); err != nil {
+			cmd.PrintErrln("Error saving config:", err)
+			return
+		}
+		fmt.Printf("Imported %d holiday(s), %d new.\n", len(holidays), added)
+	},
+}
+
+// parseMonth parses a month like "nov", "november 2027" or "2027-11". Without a year, the current year is used.
+func parseMonth(s string, now time.Time) (time.Time, error) {
+	for _, layout := range monthLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		year := t.Year()
+		if !strings.Contains(layout, "2006") {
+			year = now.Year()
+		}
+		return time.Date(year, t.Month(), 1, 0, 0, 0, 0, time.Local), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid month %q: expected e.g. nov, \"nov 2027\" or 2027-11", s)
+}
+
+// dueCounts counts the unfinished tasks due on each day
+func dueCounts(all []types.Task) map[string]int {
+	counts := make(map[string]int)
+	for _, t := range all {
+		if t.Status == constants.TaskStatus.Complete {
+			continue
+		}
+		counts[t.Due().Format(time.DateOnly)]++
+	}
+	return counts
+}
+
+// printMonth prints a calendar grid for the month, with a column per day of the week starting from weekStart
+func printMonth(month time.Time, counts map[string]int, cal calendar.Calendar, weekStart time.Weekday) {
+	const cellWidth = 7
+	dayOff := color.New(color.FgHiBlack)
+	todayColor := color.New(color.ReverseVideo)
+	today := time.Now().Format(time.DateOnly)
+
+	title := month.Format("January 2006")
+	fmt.Printf("%*s\n", (cellWidth*7+len(title))/2, title)
+	names := make([]string, 7)
+	for i := range names {
+		names[i] = fmt.Sprintf("%-*s", cellWidth, time.Weekday((int(weekStart) + i) % 7).String()[:3])
+	}
+	fmt.Println(strings.TrimSpace(strings.Join(names, "")))
+
+	// pad the first week up to the first day of the month
+	offset := (int(month.Weekday()) - int(weekStart) + 7) % 7
+	fmt.Print(strings.Repeat(" ", offset*cellWidth))
+
+	var holidays []string
+	total := 0
+	for d := month; d.Month() == month.Month(); d = d.AddDate(0, 0, 1) {
+		day := d.Format(time.DateOnly)
+		dayNum := fmt.Sprintf("%2d", d.Day())
+		count := ""
+		if n := counts[day]; n > 0 {
+			count = fmt.Sprintf(" (%d)", n)
+			total += n
+		}
+		// pad before coloring, since the color codes would count towards the width
+		padding := strings.Repeat(" ", max(cellWidth-len(dayNum)-len(count), 1))
+
+		switch {
+		case day == today:
+			dayNum = todayColor.Sprint(dayNum)
+		case !cal.IsWorkDay(d):
+			dayNum = dayOff.Sprint(dayNum)
+			count = dayOff.Sprint(count)
+		}
+		if name, ok := cal.Holiday(d); ok {
+			holidays = append(holidays, fmt.Sprintf("  %s  %s", d.Format("Mon Jan 2"), name))
+		}
+		endOfWeek := (int(d.Weekday())-int(weekStart)+7)%7 == 6
+		if endOfWeek || d.AddDate(0, 0, 1).Month() != month.Month() {
+			fmt.Println(dayNum + count)
+		} else {
+			fmt.Print(dayNum + count + padding)
+		}
+	}
+
+	if len(holidays) > 0 {
+		fmt.Println("\nHolidays:")
+		fmt.Println(strings.Join(holidays, "\n"))
+	}
+	fmt.Printf("\n%d unfinished task(s) due this month.\n", total)
+}
+
+func init() {
+	calCmd.AddCommand(calImportCmd)
+	rootCmd.AddCommand(calCmd)
+}
@@ -11,22 +11,29 @@ import (
 
 // compCmd represents the comp command
 var compCmd = &cobra.Command{
-	Use:   "comp",
+	Use:   "comp <task>...",
 	Short: "Marks the given task as completed",
 	Long: `Marks the given task as completed. The task is archived and removed from the active task list.
 	
 Example usage:
 
 # mark task with ID beginning with 9bc3 as completed
-task comp 9bc3`,
+task comp 9bc3
+
+# a task can also be given by words from its title; if several tasks match, you pick one from a list
+task comp "deploy fix"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) < 1 {
 			cmd.PrintErrln("task ID required")
 			return
 		}
-		// all arguments will be task IDs
-		for i, taskID := range args {
-			if err := tasks.CompleteTask(taskID); err != nil {
+		// each argument is a task
+		for i, ref := range args {
+			taskID, err := resolveTaskIDToChange(ref, "Complete")
+			if err == nil {
+				err = tasks.CompleteTask(taskID)
+			}
+			if err != nil {
 				cmd.PrintErrln(err)
 				if i == 0 {
 					return // no tasks were completed, so quit without showing summary
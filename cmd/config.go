@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/util"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change settings",
+	Long: `View and change the settings stored in the config file (~/.config/task/config.toml).
+
+Any setting can be overridden with an env var named TASK_ followed by the key in upper case,
+with dots replaced by underscores. e.g. TASK_EDITOR overrides "editor" and TASK_THEME_LATE overrides "theme.late".
+
+Example usage:
+
+# show all settings
+task config list
+
+# make new tasks due tomorrow by default
+task config set default_due 1d
+
+# show the category column in the task table
+task config set columns id,title,cat,due,status,pr,upd
+
+# edit the config file directly
+task config edit`,
+	Annotations: noDatabase(),
+}
+
+var configGetCmd = &cobra.Command{
+	Use:         "get <key>",
+	Short:       "Show the value of a setting",
+	Args:        cobra.ExactArgs(1),
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.Load()
+		if err != nil {
+			cmd.PrintErrln("Error loading config:", err)
+			return
+		}
+		v, err := c.Value(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		fmt.Println(v)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:         "set <key> <value>",
+	Short:       "Change the value of a setting",
+	Args:        cobra.ExactArgs(2),
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		key, value := args[0], args[1]
+		if key == "columns" {
+			if err := validateColumns(value); err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+		}
+		if err := config.Set(key, value); err != nil {
+			cmd.PrintErrln("Error setting config:", err)
+			return
+		}
+		if _, overridden := os.LookupEnv(config.EnvVar(key)); overridden {
+			fmt.Printf("Note: %s is currently overridden by $%s\n", key, config.EnvVar(key))
+		}
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "Show all settings",
+	Args:        cobra.NoArgs,
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.Load()
+		if err != nil {
+			cmd.PrintErrln("Error loading config:", err)
+			return
+		}
+		for _, s := range config.Settings() {
+			v, _ := c.Value(s.Key)
+			line := fmt.Sprintf("%s = %q", s.Key, v)
+			if _, overridden := os.LookupEnv(config.EnvVar(s.Key)); overridden {
+				line += fmt.Sprintf("  (from $%s)", config.EnvVar(s.Key))
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:         "edit",
+	Short:       "Edit the config file in your editor",
+	Args:        cobra.NoArgs,
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := config.Path()
+		// start from a file with all the current values, so it's clear what can be set
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			c, _ := config.LoadFile()
+			if err := config.Save(c); err != nil {
+				cmd.PrintErrln("Error creating config file:", err)
+				return
+			}
+		}
+		if err := util.EditFile(path); err != nil {
+			cmd.PrintErrln("Error running editor:", err)
+			return
+		}
+		c, err := config.LoadFile()
+		if err != nil {
+			cmd.PrintErrln("Warning: config file is invalid and will be ignored:", err)
+			return
+		}
+		if err := validateColumns(strings.Join(c.Columns, ",")); err != nil {
+			cmd.PrintErrln("Warning:", err)
+		}
+	},
+}
+
+// validateColumns checks that all of the comma separated column names exist
+func validateColumns(value string) error {
+	_, err := tasks.ParseColumns(value)
+	return err
+}
+
+func init() {
+	keyCompletion := func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		keys := make([]string, 0)
+		for _, s := range config.Settings() {
+			keys = append(keys, s.Key)
+		}
+		return completions.MatchFromListCompletionFn(toComplete, keys, cmd)
+	}
+	configGetCmd.ValidArgsFunction = keyCompletion
+	configSetCmd.ValidArgsFunction = keyCompletion
+
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configEditCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/notify"
+	"github.com/webbben/task/internal/reminders"
+)
+
+var (
+	daemonNotifiers string
+	daemonBefore    time.Duration
+	daemonInterval  time.Duration
+	daemonOnce      bool
+)
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "send reminders for tasks that are due soon or overdue",
+	Long: `Watch the workspace and send a reminder when a task is due soon or overdue.
+
+Tasks due at a time of day are reminded about shortly before they're due (the reminders.before config value),
+and tasks due on a date on the day they're due. Overdue tasks get one more reminder. Each reminder is only sent
+once, even if the daemon is restarted; rescheduling a task makes it eligible again.
+
+Reminders are sent with the notifiers in the reminders.notifiers config value (or --notify):
+  log      print to standard output
+  desktop  show a desktop notification with notify-send
+  webhook  post the reminder as JSON to reminders.webhook_url
+
+Example usage:
+
+# run in the background with desktop notifications
+task daemon --notify desktop &
+
+# send any pending reminders and exit, e.g. from cron
+task daemon --once`,
+	Args:        cobra.NoArgs,
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Get().Reminders
+		names := cfg.Notifiers
+		if cmd.Flags().Changed("notify") {
+			names = strings.Split(daemonNotifiers, ",")
+		}
+		notifiers := make(map[string]notify.Notifier)
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			n, err := notify.New(name, cfg.WebhookURL, os.Stdout)
+			if err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+			notifiers[name] = n
+		}
+		if len(notifiers) == 0 {
+			cmd.PrintErrln("no notifiers are set: use --notify or the reminders.notifiers config value")
+			return
+		}
+		before := cfg.BeforeDuration()
+		if cmd.Flags().Changed("before") {
+			before = daemonBefore
+		}
+
+		workspace := resolveWorkspace()
+		state, err := reminders.LoadState(reminders.StatePath(workspace))
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		d := &reminders.Daemon{
+			Workspace: workspace,
+			Notifiers: notifiers,
+			Before:    before,
+			Interval:  daemonInterval,
+			State:     state,
+			Log:       log.New(os.Stderr, "task daemon: ", log.LstdFlags),
+		}
+		if daemonOnce {
+			if err := d.Check(time.Now()); err != nil {
+				cmd.PrintErrln("Error checking tasks:", err)
+			}
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		d.Log.Printf("watching workspace %s", workspace)
+		if err := d.Run(ctx); err != nil {
+			cmd.PrintErrln(err)
+		}
+	},
+}
+
+func init() {
+	daemonCmd.Flags().StringVar(&daemonNotifiers, "notify", "", "comma separated notifiers: log, desktop, webhook (default: reminders.notifiers config)")
+	daemonCmd.Flags().DurationVar(&daemonBefore, "before", 0, "how long before a task due at a time of day to remind about it (default: reminders.before config)")
+	daemonCmd.Flags().DurationVar(&daemonInterval, "interval", time.Minute, "how often to check for due tasks when nothing has changed")
+	daemonCmd.Flags().BoolVar(&daemonOnce, "once", false, "check once, send any reminders and exit")
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -13,14 +13,14 @@ var (
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
-	Use:   "delete",
+	Use:   "delete [task]",
 	Short: "deletes tasks",
 	Long: `Deletes tasks from the ongoing tasks database.
 	
 Example usage:
 
-# delete a specific task
-task delete <task-id>
+# delete a specific task, by its ID or words from its title
+task delete <task>
 
 # delete all tasks
 task delete -a`,
@@ -36,7 +36,12 @@ task delete -a`,
 			cmd.PrintErrln("task ID or --all flag is required")
 			return
 		}
-		if err := tasks.DeleteTask(args[0]); err != nil {
+		taskID, err := resolveTaskIDToChange(args[0], "Delete")
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		if err := tasks.DeleteTask(taskID); err != nil {
 			cmd.PrintErrln(err)
 		}
 	},
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/dates"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+)
+
+var (
+	editTitle       string
+	editDescription string
+	editCategory    string
+	editDueDate     string
+	editTags        []string
+	editPriority    int
+	editEstimate    string
+)
+
+// editCmd represents the edit command
+var editCmd = &cobra.Command{
+	Use:   "edit <task>",
+	Short: "Edit an existing task",
+	Long: `Change the details of an existing task. Only the given flags are changed.
+The task can be given by its ID or by words from its title.
+
+Example usage:
+
+# change the due date and estimate
+task edit 9bf4 -D fri -e 3h
+
+# remove the estimate and tags
+task edit 9bf4 -e "" -T ""`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID, err := resolveTaskID(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		task, err := tasks.GetTask(taskID)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("title") {
+			if editTitle == "" {
+				cmd.PrintErrln("title can't be empty")
+				return
+			}
+			task.Title = editTitle
+		}
+		if flags.Changed("description") {
+			task.Description = editDescription
+		}
+		if flags.Changed("category") {
+			task.Category = editCategory
+		}
+		if flags.Changed("due-date") {
+			due, err := dates.ParseDue(editDueDate)
+			if err != nil {
+				cmd.PrintErrln("Error parsing due date:", err)
+				return
+			}
+			if due.Ambiguity != "" {
+				fmt.Println("Note:", due.Ambiguity)
+			}
+			task.SetDue(due.Time, due.HasTime)
+		}
+		if flags.Changed("tags") {
+			task.Tags = editTags
+		}
+		if flags.Changed("priority") {
+			task.Priority = editPriority
+		}
+		if flags.Changed("estimate") {
+			task.Estimate, err = tasks.ParseEstimate(editEstimate)
+			if err != nil {
+				cmd.PrintErrln("Error parsing estimate:", err)
+				return
+			}
+		}
+
+		if err := tasks.UpdateTask(*task); err != nil {
+			fmt.Println("Error updating task:", err)
+			return
+		}
+		tasks.PrintListOfTasks([]types.Task{*task})
+	},
+}
+
+func init() {
+	editCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	editCmd.Flags().StringVarP(&editTitle, "title", "t", "", "the new title")
+	editCmd.Flags().StringVarP(&editDescription, "description", "d", "", "a description of the task")
+	editCmd.Flags().StringVarP(&editCategory, "category", "c", "", "a category for the task")
+	editCmd.Flags().StringVarP(&editDueDate, "due-date", "D", "", "the due date for the task")
+	editCmd.Flags().StringSliceVarP(&editTags, "tags", "T", nil, "comma separated tags for the task")
+	editCmd.Flags().IntVarP(&editPriority, "priority", "p", 0, "the priority of the task")
+	editCmd.Flags().StringVarP(&editEstimate, "estimate", "e", "", "how much work the task is expected to take, e.g. 2h or 3pt (empty to remove)")
+
+	rootCmd.AddCommand(editCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/tasks"
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain <task>",
+	Short: "show how a task's urgency score is calculated",
+	Long: `Show each term that makes up a task's urgency score, and the total.
+The weights of the terms can be changed with the urgency.* config settings.
+The task can be given by its ID or by words from its title.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID, err := resolveTaskID(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		task, err := tasks.GetTask(taskID)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		score, terms := tasks.Urgency(*task, time.Now())
+		fmt.Println(task.Title)
+		if len(terms) == 0 {
+			fmt.Println("  nothing adds urgency to this task")
+		}
+		for _, term := range terms {
+			fmt.Printf("  %-12s %-28s %+7.2f\n", term.Name, term.Detail, term.Score)
+		}
+		fmt.Printf("  %-41s %7.2f\n", "urgency", score)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/ui/focus"
+)
+
+var (
+	focusWork      time.Duration
+	focusBreak     time.Duration
+	focusLongBreak time.Duration
+)
+
+// focusCmd represents the focus command
+var focusCmd = &cobra.Command{
+	Use:   "focus <task>",
+	Short: "work on a task with a pomodoro timer",
+	Long: `Launch a pomodoro timer for a task: work intervals separated by short breaks, with a long break every few intervals.
+
+The time worked is logged on the task (see "task time"), a note summarizing the session is added when it ends,
+and you're asked whether the task is complete. Pending tasks are moved to in progress when the session starts.
+
+The intervals default to the focus.* config values, e.g. "task config set focus.work 50m".
+The task can be given by its ID or by words from its title.
+
+Example:
+
+task focus 9bf4 --work 45m --break 10m`,
+	Args: cobra.ExactArgs(1),
+	// the TUI opens the database itself, only while saving
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := config.Get().Focus
+		work, brk, longBreak := c.Durations()
+		opts := focus.Options{Work: work, Break: brk, LongBreak: longBreak, LongBreakEvery: c.LongBreakEvery}
+		if focusWork > 0 {
+			opts.Work = focusWork
+		}
+		if focusBreak > 0 {
+			opts.Break = focusBreak
+		}
+		if focusLongBreak > 0 {
+			opts.LongBreak = focusLongBreak
+		}
+		ws := resolveWorkspace()
+		taskID, err := resolveTaskIDInWorkspace(ws, args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		if err := focus.Run(ws, taskID, opts); err != nil {
+			cmd.PrintErrln(err)
+		}
+	},
+}
+
+func init() {
+	focusCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	rootCmd.AddCommand(focusCmd)
+	focusCmd.Flags().DurationVar(&focusWork, "work", 0, "length of a work interval (default: focus.work config)")
+	focusCmd.Flags().DurationVar(&focusBreak, "break", 0, "length of a short break (default: focus.break config)")
+	focusCmd.Flags().DurationVar(&focusLongBreak, "long-break", 0, "length of a long break (default: focus.long_break config)")
+}
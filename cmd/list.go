@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/config"
 	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/storage"
 	"github.com/webbben/task/internal/tasks"
 	"github.com/webbben/task/internal/types"
 	"github.com/webbben/task/internal/util"
@@ -16,6 +21,10 @@ var (
 	filterBy string
 	limit    int
 	todo     bool
+	columns  string
+	showAll  bool
+
+	allWorkspaces bool
 )
 
 // listCmd represents the list command
@@ -29,8 +38,8 @@ Example usage:
 # list all tasks
 task list
 
-# sort by a property (title, category, due date, priority, and status are supported)
-task list -s duedate
+# sort by a property (urgency, title, category, duedate, priority, and status are supported)
+task list -s urgency
 
 # filter by a property value (status, category, priority, and due date are supported)
 task list -f status=paused
@@ -38,34 +47,96 @@ task list -f status=paused
 # limit the number of results shown
 task list -l 5
 
-# sort the list to show the most important tasks for today (cannot be used with sort or filter)
+# show the most urgent tasks, most urgent first (cannot be used with sort or filter)
+# tasks below the urgency.todo_min config value are left out; see "task explain" for how urgency is scored
 task list -t
+
+# include snoozed tasks, with the date they wake up (see "task snooze")
+task list --all
+
+# list the tasks of every workspace, labeled with the workspace each one is from
+task list --all-workspaces
+
+# choose which columns to show (id, title, cat, due, status, pr, upd, tags, urg, est, spent, wait, ws)
+task list --columns id,title,cat,due,tags
 	`,
+	Annotations: readOnly(),
 	Run: func(cmd *cobra.Command, args []string) {
+		cols := tasks.DefaultColumns()
+		if columns != "" {
+			var err error
+			cols, err = tasks.ParseColumns(columns)
+			if err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+		}
+
 		// load all tasks
-		t, err := tasks.GetAllTasks()
+		var t []types.Task
+		var err error
+		if allWorkspaces {
+			t, err = loadAllWorkspaces()
+			cols = tasks.WithColumn(cols, "ws", "id")
+		} else {
+			t, err = loadTasks()
+		}
 		if err != nil {
 			cmd.PrintErrln("Error loading tasks:", err)
 			return
 		}
-		todaysCompTasks, err := tasks.GetCompletedTasks(util.RoundDateDown(time.Now()))
-		if err != nil {
-			cmd.PrintErrln("Error loading completed tasks:", err)
-			return
+
+		// snoozed tasks are hidden until they wake up
+		if showAll {
+			cols = tasks.WithColumn(cols, "wait", "due")
+		} else {
+			now := time.Now()
+			t = filterTasks(t, func(t types.Task) bool { return t.Waiting(now) })
 		}
-		t = append(t, todaysCompTasks...)
 
 		// check for filtering
 		// todo flag (-t) has priority over filter flag (-f) and sort flag (-s)
 		if todo {
-			showTodoTasks(t)
+			showTodoTasks(t, cols)
 			return
 		}
 
-		tasks.PrintListOfTasks(t)
+		if sortBy != "" {
+			less, ok := sortFuncs[sortBy]
+			if !ok {
+				cmd.PrintErrf("invalid sort %q: expected one of %s\n", sortBy, strings.Join(sortNames(), ", "))
+				return
+			}
+			if less == nil {
+				tasks.SortByUrgency(t)
+			} else {
+				sort.SliceStable(t, func(i, j int) bool { return less(t[i], t[j]) })
+			}
+		}
+
+		tasks.PrintTable(t, cols)
 	},
 }
 
+// sortFuncs are the properties the list can be sorted by. Urgency has no compare function, since it's sorted by tasks.SortByUrgency.
+var sortFuncs = map[string]func(a, b types.Task) bool{
+	"urgency":  nil,
+	"title":    func(a, b types.Task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) },
+	"category": func(a, b types.Task) bool { return strings.ToLower(a.Category) < strings.ToLower(b.Category) },
+	"duedate":  func(a, b types.Task) bool { return a.Due().Before(b.Due()) },
+	"priority": func(a, b types.Task) bool { return a.Priority > b.Priority },
+	"status":   func(a, b types.Task) bool { return a.Status > b.Status },
+}
+
+func sortNames() []string {
+	names := make([]string, 0, len(sortFuncs))
+	for name := range sortFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 
@@ -73,28 +144,78 @@ func init() {
 	listCmd.Flags().StringVarP(&filterBy, "filter", "f", "", "Filter the list by a property value")
 	listCmd.Flags().IntVarP(&limit, "limit", "l", 0, "Limit the number of results shown")
 	listCmd.Flags().BoolVarP(&todo, "todo", "t", false, "Show the most important tasks for today")
+	listCmd.Flags().BoolVarP(&showAll, "all", "a", false, "Include snoozed tasks")
+	listCmd.Flags().BoolVar(&allWorkspaces, "all-workspaces", false, "Show the tasks of all workspaces")
+	listCmd.Flags().StringVar(&columns, "columns", "", "Comma separated columns to show (defaults to the columns config setting)")
+	listCmd.RegisterFlagCompletionFunc("columns", columnsCompletion)
+	listCmd.RegisterFlagCompletionFunc("sort", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completions.MatchFromListCompletionFn(toComplete, sortNames(), cmd)
+	})
 }
 
-func showTodoTasks(t []types.Task) {
-	t = filterTasks(t, func(t types.Task) bool {
-		if t.Status == constants.TaskStatus.Complete {
-			return true
+// columnsCompletion completes the last column name in a comma separated list of columns
+func columnsCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	options := make([]string, 0)
+	for _, name := range tasks.ColumnNames() {
+		options = append(options, prefix+name)
+	}
+	matches, directive := completions.MatchFromListCompletionFn(toComplete, options, cmd)
+	return matches, directive | cobra.ShellCompDirectiveNoSpace
+}
+
+// loadTasks loads all active tasks and the tasks completed today from the open database
+func loadTasks() ([]types.Task, error) {
+	t, err := tasks.GetAllTasks()
+	if err != nil {
+		return nil, fmt.Errorf("active tasks: %w", err)
+	}
+	todaysCompTasks, err := tasks.GetCompletedTasks(util.RoundDateDown(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("completed tasks: %w", err)
+	}
+	return append(t, todaysCompTasks...), nil
+}
+
+// loadAllWorkspaces opens each workspace in turn and loads its tasks, labeling each one with the workspace it came from
+func loadAllWorkspaces() ([]types.Task, error) {
+	names, err := storage.ListWorkspaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	out := make([]types.Task, 0)
+	for _, name := range names {
+		storage.CloseDatabase()
+		if err := storage.OpenWorkspace(name, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}); err != nil {
+			return nil, fmt.Errorf("failed to open workspace %s: %w", name, err)
 		}
-		// task is due later than tomorrow
-		if t.DueDate.After(util.RoundDateUp(time.Now().AddDate(0, 0, 1))) {
-			return true
+		t, err := loadTasks()
+		if err != nil {
+			return nil, fmt.Errorf("workspace %s: %w", name, err)
 		}
-		return false
-	})
-	// sort by due date, but for tasks that are the same due date, sort by priority
-	sort.Slice(t, func(i, j int) bool {
-		if t[i].DueDate.Equal(t[j].DueDate) {
-			return t[i].Priority > t[j].Priority
+		for i := range t {
+			t[i].Workspace = name
 		}
-		return t[i].DueDate.Before(t[j].DueDate)
+		out = append(out, t...)
+	}
+	return out, nil
+}
+
+// showTodoTasks shows the tasks that are urgent enough to work on, most urgent first.
+// Tasks that woke up from a snooze today are always shown first, so they aren't missed.
+func showTodoTasks(t []types.Task, cols []tasks.Column) {
+	minScore := config.Get().Urgency.TodoMin
+	now := time.Now()
+	t = filterTasks(t, func(t types.Task) bool {
+		return t.Status == constants.TaskStatus.Complete || (!t.WokeOn(now) && tasks.UrgencyScore(t) < minScore)
 	})
+	tasks.SortByUrgency(t)
+	sort.SliceStable(t, func(i, j int) bool { return t[i].WokeOn(now) && !t[j].WokeOn(now) })
 
-	tasks.PrintListOfTasks(t)
+	tasks.PrintTable(t, tasks.WithColumn(cols, "urg", "title"))
 }
 
 // filterTasks takes a filterFunc which is used to filter out tasks.
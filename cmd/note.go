@@ -2,11 +2,12 @@ package cmd
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/storage"
 	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
 	"github.com/webbben/task/internal/util"
 )
 
@@ -16,20 +17,35 @@ var noteCmd = &cobra.Command{
 	Short: "create a new note update for a task",
 	Long: `Create a new note for an existing task. You can specify a note, or leave it blank to launch an editor.
 
+Each note gets a short ID, which is used to edit, remove or move it. Note IDs can be shortened to any unique prefix.
+The task can be given by its ID or by words from its title.
+
 # add short note
 task note 9bc3 "will follow-up next Monday"
+task note "quarterly report" "sent the draft to Sam"
 
 # add a note that is composed in a terminal editor
-task note 3bp4`,
+task note 3bp4
+
+# edit, remove or move a note
+task note edit 3bp4 a81f
+task note rm 3bp4 a81f
+task note mv 3bp4 a81f 9bc3`,
+	// the database isn't kept open while the note is written in the editor
+	Annotations: noDatabase(),
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			cmd.PrintErrln("task ID required")
 			return
 		}
-		taskID := args[0]
+		ws := resolveWorkspace()
+		taskID, err := resolveTaskIDInWorkspace(ws, args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
 
 		// get note to add to task
-		today := time.Now().Format("1-2-2006 15:04")
 		note := ""
 		if len(args) >= 2 {
 			note = args[1]
@@ -41,15 +57,142 @@ task note 3bp4`,
 			fmt.Println("No note was entered.")
 			return
 		}
-		if err := tasks.AddNote(taskID, note, today); err != nil {
+		var added types.Note
+		err = storage.WithWorkspace(ws, storage.OpenOptions{Timeout: lockTimeout}, func() error {
+			var err error
+			added, err = tasks.AddNote(taskID, note, "")
+			return err
+		})
+		if err != nil {
 			cmd.PrintErrln("Error adding note:", err)
 			return
 		}
-		fmt.Printf("Added note \"%s\" to task %s: \n\"%s\"", today, taskID, note)
+		fmt.Printf("Added note %s (%s) to task %s: \n\"%s\"\n", added.ID, added.Name(), taskID, note)
+	},
+}
+
+var noteEditCmd = &cobra.Command{
+	Use:   "edit <task> <note-id> [note]",
+	Short: "edit a note",
+	Long: `Replace the content of a note. If no new content is given, the note is opened in an editor.
+
+# rewrite a note in the editor
+task note edit 3bp4 a81f
+
+# replace a note directly
+task note edit 3bp4 a81f "follow-up moved to Tuesday"`,
+	Args: cobra.RangeArgs(2, 3),
+	// the database isn't kept open while the note is edited in the editor
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		ws := resolveWorkspace()
+		taskID, err := resolveTaskIDInWorkspace(ws, args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		var original types.Note
+		err = storage.WithWorkspace(ws, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}, func() error {
+			task, err := tasks.GetTask(taskID)
+			if err != nil {
+				return err
+			}
+			i, err := tasks.FindNote(*task, args[1])
+			if err != nil {
+				return err
+			}
+			original = task.Notes[i]
+			return nil
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		content := ""
+		if len(args) == 3 {
+			content = args[2]
+		} else {
+			content, err = util.EditText(original.Content)
+			if err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+		}
+		if content == "" {
+			cmd.PrintErrln("Note is empty; use \"task note rm\" to remove it.")
+			return
+		}
+		if content == original.Content {
+			fmt.Println("Note unchanged.")
+			return
+		}
+		err = storage.WithWorkspace(ws, storage.OpenOptions{Timeout: lockTimeout}, func() error {
+			_, err := tasks.EditNote(taskID, original.ID, content)
+			return err
+		})
+		if err != nil {
+			cmd.PrintErrln("Error editing note:", err)
+			return
+		}
+		fmt.Printf("Updated note %s on task %s\n", original.ID, taskID)
+	},
+}
+
+var noteRmCmd = &cobra.Command{
+	Use:   "rm <task> <note-id>",
+	Short: "remove a note",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID, err := resolveTaskIDToChange(args[0], "Remove the note from")
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		note, err := tasks.DeleteNote(taskID, args[1])
+		if err != nil {
+			cmd.PrintErrln("Error removing note:", err)
+			return
+		}
+		fmt.Printf("Removed note %s (%s) from task %s\n", note.ID, note.Name(), taskID)
+	},
+}
+
+var noteMvCmd = &cobra.Command{
+	Use:   "mv <task> <note-id> <new-task>",
+	Short: "move a note to another task",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := resolveTaskIDToChange(args[0], "Move the note from")
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		to, err := resolveTaskIDToChange(args[2], "Move the note to")
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		note, err := tasks.MoveNote(from, args[1], to)
+		if err != nil {
+			cmd.PrintErrln("Error moving note:", err)
+			return
+		}
+		fmt.Printf("Moved note %s (%s) from task %s to %s\n", note.ID, note.Name(), from, to)
 	},
 }
 
 func init() {
 	noteCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	noteEditCmd.ValidArgsFunction = completions.NoteIDCompletionFn()
+	noteRmCmd.ValidArgsFunction = completions.NoteIDCompletionFn()
+	noteMvCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// the new task is completed like the first one
+		if len(args) == 2 {
+			return completions.TaskIDCompletionFn(false)(cmd, args, toComplete)
+		}
+		return completions.NoteIDCompletionFn()(cmd, args, toComplete)
+	}
+	noteCmd.AddCommand(noteEditCmd, noteRmCmd, noteMvCmd)
 	rootCmd.AddCommand(noteCmd)
 }
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+// openCmd represents the open command
+var openCmd = &cobra.Command{
+	Use:   "open <task> [n]",
+	Short: "open a task's attachment",
+	Long: `Open the nth attachment of a task (as numbered in "task view") with the default application, using xdg-open.
+If the task only has one attachment, n can be left out. The task can be given by its ID or by words from its title.
+
+Example:
+
+task open 9bf4 2`,
+	Args:        cobra.RangeArgs(1, 2),
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID, err := resolveTaskID(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		task, err := tasks.GetTask(taskID)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		if len(task.Attachments) == 0 {
+			cmd.PrintErrln("task has no attachments")
+			return
+		}
+
+		n := 1
+		if len(args) == 2 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 || n > len(task.Attachments) {
+				cmd.PrintErrf("invalid attachment number %s: task has %d attachment(s)\n", args[1], len(task.Attachments))
+				return
+			}
+		} else if len(task.Attachments) > 1 {
+			cmd.PrintErrln("task has multiple attachments, choose one:")
+			for i, a := range task.Attachments {
+				cmd.PrintErrf("  %d. %s\n", i+1, a.Name)
+			}
+			return
+		}
+
+		a := task.Attachments[n-1]
+		target, err := attachmentTarget(a)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		if err := util.Open(target); err != nil {
+			cmd.PrintErrln("Error opening attachment:", err)
+			return
+		}
+		fmt.Println("Opened", a.Name)
+	},
+}
+
+// attachmentTarget returns what to open for an attachment. Stored files are linked under their original name in a temp
+// directory, since the store names them by hash and applications pick how to open a file by its extension.
+func attachmentTarget(a types.Attachment) (string, error) {
+	if a.IsURL() {
+		return a.URL, nil
+	}
+	blob := storage.BlobPath(a.Hash)
+	if _, err := os.Stat(blob); err != nil {
+		return "", fmt.Errorf("stored file for %s is missing: %w", a.Name, err)
+	}
+	dir := filepath.Join(os.TempDir(), "task-attachments", a.Hash[:12])
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	link := filepath.Join(dir, filepath.Base(a.FileName))
+	if _, err := os.Lstat(link); err == nil {
+		return link, nil
+	}
+	return link, os.Symlink(blob, link)
+}
+
+func init() {
+	openCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	rootCmd.AddCommand(openCmd)
+}
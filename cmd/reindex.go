@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/tasks"
+)
+
+// reindexCmd represents the reindex command
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "rebuild the search index used by task search",
+	Long: `Rebuild the search index from every active and completed task.
+The index is updated whenever tasks change, so this is only needed if it's out of date, e.g. after using an older version of task.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		n, err := tasks.RebuildIndex()
+		if err != nil {
+			cmd.PrintErrln("Error rebuilding the search index:", err)
+			return
+		}
+		fmt.Printf("Indexed %d task(s).\n", n)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/ui/picker"
+	"github.com/webbben/task/internal/util"
+)
+
+// resolveTaskID finds the ID of the active task an argument refers to: its ID, an ID prefix, or words from its title.
+// If several tasks match, the user picks one when running in a terminal.
+func resolveTaskID(ref string) (string, error) {
+	return pickIfAmbiguous(tasks.ResolveTask(ref))
+}
+
+// resolveTaskIDToChange is resolveTaskID for commands that can't be undone, like delete. If the task was only found by
+// part of its title, the user is asked to confirm it's the right one.
+func resolveTaskIDToChange(ref, action string) (string, error) {
+	t, certain, err := tasks.ResolveTaskMatch(ref)
+	// picking a task from the list is confirmation enough
+	if err != nil || certain {
+		return pickIfAmbiguous(t, err)
+	}
+	if !util.Confirm(fmt.Sprintf("%q matches %s (%s). %s it?", ref, util.Truncate(t.Title, 50), t.ID, action)) {
+		return "", fmt.Errorf("task %s was not changed", t.ID)
+	}
+	return t.ID, nil
+}
+
+// resolveTaskIDInWorkspace is resolveTaskID for commands that don't keep the database open.
+// the database is only opened while the task is looked up, and is closed again before the picker opens.
+func resolveTaskIDInWorkspace(ws, ref string) (string, error) {
+	var t types.Task
+	err := storage.WithWorkspace(ws, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}, func() error {
+		var err error
+		t, err = tasks.ResolveTask(ref)
+		return err
+	})
+	return pickIfAmbiguous(t, err)
+}
+
+// pickIfAmbiguous returns the ID of a resolved task, or lets the user pick one of the matches if the reference was ambiguous.
+// it's separate from resolveTaskID so commands that open the database themselves can close it while the picker is open.
+func pickIfAmbiguous(t types.Task, err error) (string, error) {
+	var ambiguous *tasks.AmbiguousError
+	if !errors.As(err, &ambiguous) || !interactive() {
+		return t.ID, err
+	}
+	picked, ok, err := picker.Pick(fmt.Sprintf("%q matches %d tasks", ambiguous.Ref, len(ambiguous.Matches)), ambiguous.Matches)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors.New("no task was picked")
+	}
+	return picked.ID, nil
+}
+
+// interactive reports whether the user is at a terminal, so they can be prompted
+func interactive() bool {
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
@@ -2,8 +2,24 @@ package cmd
 
 import (
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/ui/dashboard"
+)
+
+// readOnlyAnnotation marks commands that never write to the database, so it can be opened read-only for them.
+// this lets them run alongside other task processes that have the database open.
+const readOnlyAnnotation = "readonly"
+
+// noDatabaseAnnotation marks commands that manage database files themselves, so no database is opened for them.
+const noDatabaseAnnotation = "nodb"
+
+var (
+	lockTimeout time.Duration
+	workspace   string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -17,10 +33,74 @@ Basic examples:
 task list
 
 # add task
-task add "write some code"`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	// Run: func(cmd *cobra.Command, args []string) { },
+task add "write some code"
+
+# open the interactive dashboard
+task`,
+	// the dashboard opens the database itself, only while reading or writing, so it doesn't lock out other commands
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dashboard.Run(resolveWorkspace()); err != nil {
+			cmd.PrintErrln(err)
+		}
+	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return openDatabase(cmd)
+	},
+}
+
+// openDatabase opens the task database in the mode required by the given command
+func openDatabase(cmd *cobra.Command) error {
+	if cmd.Annotations[noDatabaseAnnotation] == "true" {
+		return nil
+	}
+	ws := resolveWorkspace()
+	opts := storage.OpenOptions{
+		ReadOnly: cmd.Annotations[readOnlyAnnotation] == "true",
+		Timeout:  lockTimeout,
+	}
+	// shell completions should never make the user wait; if the database is locked they just won't find any tasks
+	if isCompletionCmd(cmd) {
+		opts.ReadOnly = true
+		opts.Timeout = 0
+		storage.OpenWorkspace(ws, opts)
+		return nil
+	}
+	if err := storage.OpenWorkspace(ws, opts); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	return nil
+}
+
+// resolveWorkspace decides which workspace to use. In order of priority: the --workspace flag, the workspace
+// in the config (which can be overridden with $TASK_WORKSPACE), and finally the default workspace.
+func resolveWorkspace() string {
+	if workspace != "" {
+		return workspace
+	}
+	if configured := config.Get().Workspace; configured != "" {
+		return configured
+	}
+	return storage.DefaultWorkspace
+}
+
+func isCompletionCmd(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, "completion":
+		return true
+	}
+	return cmd.HasParent() && cmd.Parent().Name() == "completion"
+}
+
+// readOnly returns the annotations used to mark a command as read-only
+func readOnly() map[string]string {
+	return map[string]string{readOnlyAnnotation: "true"}
+}
+
+// noDatabase returns the annotations used to mark a command as not needing the database opened
+func noDatabase() map[string]string {
+	return map[string]string{noDatabaseAnnotation: "true"}
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -28,10 +108,12 @@ task add "write some code"`,
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
+		storage.CloseDatabase()
 		os.Exit(1)
 	}
 }
 
 func init() {
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().StringVarP(&workspace, "workspace", "w", "", "the workspace to use (defaults to $"+config.EnvVar("workspace")+", then the workspace set by 'task workspace switch')")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", storage.DefaultOpenOptions.Timeout, "how long to wait if another task process has the database locked")
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/rpc"
+)
+
+// rpcCmd represents the rpc command
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Serve JSON-RPC 2.0 over stdin/stdout",
+	Long: `Start a long-lived process that speaks line-delimited JSON-RPC 2.0 over stdin and stdout.
+This is meant for editor plugins and other integrations, so they don't need to spawn a new task process for each request.
+
+Each request and response is a single line of JSON. Supported methods:
+
+  addTask              {"title", "description", "category", "tags", "due_date", "due_has_time"}
+  getTask              {"id"}
+  getAllTasks
+  addNote              {"id", "note", "title"} ("name" is still accepted for "title")
+  completeTask         {"id"}
+  findTasksByIDPrefix  {"prefix"}
+
+Whenever a task is changed, a "tasksChanged" notification is sent with {"action", "id"}.
+
+Example:
+
+echo '{"jsonrpc":"2.0","id":1,"method":"getAllTasks"}' | task rpc`,
+	Args: cobra.NoArgs,
+	// the server opens the database itself for each request, so it doesn't lock out other commands while it's running
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		server := rpc.NewServer(os.Stdin, os.Stdout, resolveWorkspace(), lockTimeout)
+		if err := server.Serve(); err != nil {
+			cmd.PrintErrln("rpc server stopped:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/tasks"
+)
+
+// width of the snippets of descriptions and notes shown under the matching tasks
+const snippetWidth = 80
+
+var searchLimit int
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "search the titles, descriptions and notes of active and completed tasks",
+	Long: `Search the titles, descriptions and notes of all tasks, including completed ones, and show the best matches first.
+Tasks match when they contain every word of the query. Matches in the title count the most, and recently updated tasks rank higher.
+
+"quoted phrases" have to appear in that order, and a word ending in * matches every word starting with it.
+Completed tasks are shown with the date they were completed instead of their ID.
+
+Example usage:
+
+task search invoice
+task search "release notes" migrat*`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		q, err := tasks.ParseQuery(strings.Join(args, " "))
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		results, err := tasks.Search(q, time.Now())
+		if errors.Is(err, tasks.ErrNoIndex) {
+			cmd.PrintErrln("The search index hasn't been built yet. Run 'task reindex' to build it; after that it's kept up to date automatically.")
+			return
+		}
+		if err != nil {
+			cmd.PrintErrln("Error searching tasks:", err)
+			return
+		}
+		if len(results) == 0 {
+			fmt.Println("No tasks found.")
+			return
+		}
+
+		hl := color.New(color.Bold, color.FgHiYellow)
+		mark := func(s string) string { return hl.Sprint(s) }
+		dim := color.New(color.FgHiBlack)
+		shown := results
+		if searchLimit > 0 && len(shown) > searchLimit {
+			shown = shown[:searchLimit]
+		}
+		for _, r := range shown {
+			label := fmt.Sprintf("%-12s", r.Task.ID)
+			if r.Archived {
+				label = dim.Sprintf("%-12s", "done "+r.Task.LastUpdate.Format("Jan 02"))
+			}
+			line := label + "  " + tasks.Highlight(r.Task.Title, q, mark)
+			if r.Task.Category != "" {
+				line += dim.Sprintf("  [%s]", r.Task.Category)
+			}
+			fmt.Println(line)
+			if snippet := tasks.Snippet(r.Task, q, snippetWidth); snippet != "" {
+				fmt.Println(strings.Repeat(" ", 14) + tasks.Highlight(snippet, q, mark))
+			}
+		}
+		if len(shown) < len(results) {
+			fmt.Printf("\n%d more match(es); use --limit 0 to show all.\n", len(results)-len(shown))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "maximum number of tasks to show, or 0 for all")
+}
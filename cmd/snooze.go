@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/dates"
+	"github.com/webbben/task/internal/tasks"
+)
+
+var snoozeClear bool
+
+// snoozeCmd represents the snooze command
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze <task> <date>",
+	Short: "hide a task from the task list until a later date",
+	Long: `Hide a task from "task list" and "task list -t" until the given date. Use "task list --all" to see snoozed tasks.
+On the day a task wakes up, it's shown at the top of "task list -t".
+
+The date can be anything the due date accepts, e.g. 3d, 2bd, mon, "next week" or 2026-11-03.
+The task can be given by its ID or by words from its title.
+
+Example usage:
+
+# hide a task until monday
+task snooze 9bf4 mon
+
+# show it again now
+task snooze 9bf4 --clear`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID, err := resolveTaskID(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		if snoozeClear {
+			if err := tasks.SnoozeTask(taskID, time.Time{}); err != nil {
+				cmd.PrintErrln("Error waking task:", err)
+				return
+			}
+			fmt.Println("Task is no longer snoozed.")
+			return
+		}
+		if len(args) != 2 {
+			cmd.PrintErrln("a date is needed to snooze until, e.g. \"task snooze <task> 3d\"")
+			return
+		}
+
+		until, err := dates.Parse(strings.Join(args[1:], " "))
+		if err != nil {
+			cmd.PrintErrln("Error parsing date:", err)
+			return
+		}
+		if !until.Time.After(time.Now()) {
+			cmd.PrintErrln("the date to snooze until must be in the future")
+			return
+		}
+		if until.Ambiguity != "" {
+			fmt.Println("Note:", until.Ambiguity)
+		}
+		if err := tasks.SnoozeTask(taskID, until.Time); err != nil {
+			cmd.PrintErrln("Error snoozing task:", err)
+			return
+		}
+		layout := "Mon Jan 2"
+		if until.HasTime {
+			layout += " 15:04"
+		}
+		fmt.Println("Snoozed until", until.Time.Format(layout))
+	},
+}
+
+func init() {
+	snoozeCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	snoozeCmd.Flags().BoolVarP(&snoozeClear, "clear", "c", false, "stop snoozing the task, so it's shown again")
+	rootCmd.AddCommand(snoozeCmd)
+}
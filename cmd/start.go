@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/util"
+)
+
+// startCmd represents the start command
+var startCmd = &cobra.Command{
+	Use:   "start <task>",
+	Short: "start timing work on a task",
+	Long: `Start a timer for a task. The timer keeps running until "task stop", even if the terminal is closed.
+
+Only one timer runs at a time, so starting a timer for another task stops the current one first.
+The task can be given by its ID or by words from its title.
+
+Example:
+
+task start 9bf4`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID, err := resolveTaskID(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		stopped, err := tasks.StartTimer(taskID)
+		if err != nil {
+			cmd.PrintErrln("Error starting timer:", err)
+			return
+		}
+		if stopped != nil {
+			last := stopped.TimeLog[len(stopped.TimeLog)-1]
+			fmt.Printf("Stopped timer for %s after %s\n", stopped.Title, util.FormatDuration(last.Duration()))
+		}
+		fmt.Println("Started timer for", taskID)
+	},
+}
+
+func init() {
+	startCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	rootCmd.AddCommand(startCmd)
+}
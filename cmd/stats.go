@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/util"
+)
+
+const (
+	// width of the longest bar in the weekly chart
+	statsBarWidth = 20
+)
+
+var (
+	statsWeeks  int
+	statsOutput string
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "show productivity statistics and a burndown chart",
+	Long: `Show the tasks added and completed per week with bar charts, the number of open tasks at the end of each week
+as a burndown sparkline, the median cycle time (from adding a task to completing it), how many tasks were completed
+after their due date, and the throughput of each category.
+
+Use --output json to get the same statistics as JSON, e.g. for a dashboard. Cycle times are in hours.
+
+Example usage:
+
+task stats --weeks 12
+task stats -o json`,
+	Args:        cobra.NoArgs,
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if statsWeeks < 1 {
+			cmd.PrintErrln("--weeks must be at least 1")
+			return
+		}
+		if statsOutput != "text" && statsOutput != "json" {
+			cmd.PrintErrf("invalid output %q: expected text or json\n", statsOutput)
+			return
+		}
+		now := time.Now()
+		weekStart := config.Get().WeekStartDay()
+		since := util.StartOfWeek(now, weekStart).AddDate(0, 0, -7*(statsWeeks-1))
+
+		active, err := tasks.GetAllTasks()
+		if err != nil {
+			cmd.PrintErrln("Error loading tasks:", err)
+			return
+		}
+		completed, err := tasks.GetCompletedTasks(since)
+		if err != nil {
+			cmd.PrintErrln("Error loading completed tasks:", err)
+			return
+		}
+		stats := tasks.ComputeStats(append(active, completed...), since, now, weekStart)
+
+		if statsOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(stats); err != nil {
+				cmd.PrintErrln(err)
+			}
+			return
+		}
+		printStats(stats)
+	},
+}
+
+func printStats(s tasks.Stats) {
+	fmt.Printf("Stats, last %d week(s)\n\n", len(s.Weeks))
+
+	maxCount := 0
+	open := make([]int, len(s.Weeks))
+	for i, w := range s.Weeks {
+		maxCount = max(maxCount, w.Created, w.Completed)
+		open[i] = w.Open
+	}
+	fmt.Printf("%-10s  %-*s  %-*s  %4s\n", "Week of", statsBarWidth+4, "Added", statsBarWidth+4, "Completed", "Open")
+	for _, w := range s.Weeks {
+		fmt.Printf("%-10s  %3d %-*s  %3d %-*s  %4d\n", w.Start.Format("Jan 2"),
+			w.Created, statsBarWidth, util.Bar(w.Created, maxCount, statsBarWidth),
+			w.Completed, statsBarWidth, util.Bar(w.Completed, maxCount, statsBarWidth), w.Open)
+	}
+	fmt.Printf("\nOpen tasks  %s\n\n", util.Sparkline(open))
+
+	fmt.Printf("Added:              %d\n", s.Created)
+	fmt.Printf("Completed:          %d\n", s.Completed)
+	if s.MedianCycleHours > 0 {
+		fmt.Printf("Median cycle time:  %s\n", util.FormatDuration(hours(s.MedianCycleHours)))
+	}
+	if s.Completed > 0 {
+		fmt.Printf("Completed late:     %.0f%%\n", s.OverdueRate*100)
+	}
+	if s.Undated > 0 {
+		fmt.Printf("\n%d task(s) have no creation time since they were added by an older version, so they aren't counted as added or in cycle times.\n", s.Undated)
+	}
+
+	if len(s.Categories) == 0 {
+		return
+	}
+	fmt.Printf("\n%s  %9s  %8s  %11s\n", util.PadRight("Category", 14), "Completed", "Per week", "Median cycle")
+	for _, c := range s.Categories {
+		name := c.Category
+		if name == "" {
+			name = "(none)"
+		}
+		cycle := "-"
+		if c.MedianCycleHours > 0 {
+			cycle = util.FormatDuration(hours(c.MedianCycleHours))
+		}
+		fmt.Printf("%s  %9d  %8.1f  %11s\n", util.PadRight(util.Truncate(name, 14), 14), c.Completed, c.PerWeek, cycle)
+	}
+}
+
+func hours(h float64) time.Duration {
+	return time.Duration(h * float64(time.Hour))
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().IntVarP(&statsWeeks, "weeks", "n", 8, "number of weeks to show, including this one")
+	statsCmd.Flags().StringVarP(&statsOutput, "output", "o", "text", "output format: text or json")
+	statsCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completions.MatchFromListCompletionFn(toComplete, []string{"text", "json"}, cmd)
+	})
+}
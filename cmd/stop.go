@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/util"
+)
+
+// stopCmd represents the stop command
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "stop the running timer",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		task, entry, err := tasks.StopTimer()
+		if errors.Is(err, tasks.ErrNoTimer) {
+			fmt.Println("No timer is running.")
+			return
+		}
+		if err != nil {
+			cmd.PrintErrln("Error stopping timer:", err)
+			return
+		}
+		fmt.Printf("Stopped timer for %s after %s (%s total)\n", task.Title, util.FormatDuration(entry.Duration()), util.FormatDuration(task.TimeSpent()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+}
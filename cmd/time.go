@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/util"
+)
+
+// timeCmd represents the time command
+var timeCmd = &cobra.Command{
+	Use:   "time [task]",
+	Short: "show the time logged on a task",
+	Long: `Show each time entry logged on a task with "task start" and "task stop", and the total.
+Without a task, shows the task whose timer is running. The task can be given by its ID or by words from its title.`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id string
+		if len(args) == 1 {
+			var err error
+			id, err = resolveTaskID(args[0])
+			if err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+		} else {
+			running, err := tasks.RunningTimer()
+			if err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+			if running == nil {
+				fmt.Println("No timer is running.")
+				return
+			}
+			id = running.ID
+		}
+		task, err := tasks.GetTask(id)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		fmt.Println(task.Title)
+		if len(task.TimeLog) == 0 {
+			fmt.Println("No time logged.")
+			return
+		}
+		for _, e := range task.TimeLog {
+			end := "running"
+			if !e.Running() {
+				end = e.End.Format("15:04")
+				if !sameDay(e.Start, e.End) {
+					end = e.End.Format("Jan 2 15:04")
+				}
+			}
+			fmt.Printf("  %s - %-11s %8s\n", e.Start.Format("Mon Jan 2 15:04"), end, util.FormatDuration(e.Duration()))
+		}
+		fmt.Printf("Total: %s\n", util.FormatDuration(task.TimeSpent()))
+	},
+}
+
+func sameDay(a, b time.Time) bool {
+	return util.RoundDateDown(a).Equal(util.RoundDateDown(b))
+}
+
+func init() {
+	timeCmd.ValidArgsFunction = completions.TaskIDCompletionFn(true)
+	rootCmd.AddCommand(timeCmd)
+}
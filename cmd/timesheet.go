@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/dates"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/util"
+)
+
+var (
+	timesheetSince string
+	timesheetUntil string
+)
+
+// timesheetCmd represents the timesheet command
+var timesheetCmd = &cobra.Command{
+	Use:   "timesheet",
+	Short: "show the time logged per day and category",
+	Long: `Show the time logged with "task start" and "task stop" on active and completed tasks, per day and per category.
+
+By default, the current week is shown. Dates are given the same way as due dates, e.g. -7d or 10/1.
+
+Example usage:
+
+# this week
+task timesheet
+
+# the last 30 days
+task timesheet --since -30d`,
+	Args:        cobra.NoArgs,
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		since, until, err := timesheetRange()
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		active, err := tasks.GetAllTasks()
+		if err != nil {
+			cmd.PrintErrln("Error loading tasks:", err)
+			return
+		}
+		// a task completed before the start of the range can't have time logged in it
+		completed, err := tasks.GetCompletedTasks(since)
+		if err != nil {
+			cmd.PrintErrln("Error loading completed tasks:", err)
+			return
+		}
+		sheet := tasks.Timesheet(append(active, completed...), since, until)
+		fmt.Printf("Timesheet %s - %s\n\n", since.Format("Mon Jan 2"), until.Format("Mon Jan 2"))
+		if len(sheet) == 0 {
+			fmt.Println("No time logged.")
+			return
+		}
+		printTimesheet(sheet)
+	},
+}
+
+// timesheetRange returns the start and end of the timesheet. The start is rounded down to the start of its day.
+func timesheetRange() (since, until time.Time, err error) {
+	now := time.Now()
+	until = now
+	if timesheetUntil != "" {
+		until, err = dates.ParseDueDate(timesheetUntil)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --until date: %w", err)
+		}
+		until = util.RoundDateUp(until)
+	}
+
+	if timesheetSince == "" {
+		since = util.StartOfWeek(now, config.Get().WeekStartDay())
+	} else {
+		since, err = dates.ParseDueDate(timesheetSince)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --since date: %w", err)
+		}
+		since = util.RoundDateDown(since)
+	}
+	if !since.Before(until) {
+		return since, until, fmt.Errorf("--since must be before --until")
+	}
+	return since, until, nil
+}
+
+// printTimesheet prints a table with a row per day and a column per category, with totals for both
+func printTimesheet(sheet map[time.Time]map[string]time.Duration) {
+	days := make([]time.Time, 0, len(sheet))
+	catTotals := make(map[string]time.Duration)
+	for day, cats := range sheet {
+		days = append(days, day)
+		for cat, d := range cats {
+			catTotals[cat] += d
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	cats := make([]string, 0, len(catTotals))
+	for cat := range catTotals {
+		cats = append(cats, cat)
+	}
+	sort.Strings(cats)
+
+	catName := func(cat string) string {
+		if cat == "" {
+			return "(none)"
+		}
+		return cat
+	}
+	width := func(s string) int { return max(8, util.Width(s)) }
+
+	header := []string{util.PadRight("Day", 12)}
+	for _, cat := range cats {
+		header = append(header, util.PadRight(catName(cat), width(catName(cat))))
+	}
+	header = append(header, "Total")
+	fmt.Println(strings.Join(header, "  "))
+
+	row := func(label string, values map[string]time.Duration) {
+		cols := []string{util.PadRight(label, 12)}
+		var total time.Duration
+		for _, cat := range cats {
+			d, ok := values[cat]
+			value := "-"
+			if ok {
+				value = util.FormatDuration(d)
+			}
+			total += d
+			cols = append(cols, util.PadRight(value, width(catName(cat))))
+		}
+		cols = append(cols, util.FormatDuration(total))
+		fmt.Println(strings.Join(cols, "  "))
+	}
+	for _, day := range days {
+		row(day.Format("Mon Jan 2"), sheet[day])
+	}
+	row("Total", catTotals)
+}
+
+func init() {
+	rootCmd.AddCommand(timesheetCmd)
+	timesheetCmd.Flags().StringVarP(&timesheetSince, "since", "s", "", "first day of the timesheet (default: start of this week)")
+	timesheetCmd.Flags().StringVarP(&timesheetUntil, "until", "u", "", "last day of the timesheet (default: today)")
+}
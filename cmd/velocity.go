@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/util"
+)
+
+const (
+	// categories whose tasks take this much longer (or shorter) than estimated are called out
+	underestimateRatio = 1.2
+	overestimateRatio  = 0.8
+	// a category needs at least this many tasks before it's called out, so a single bad guess doesn't count as a habit
+	minAccuracyTasks = 2
+)
+
+var velocityWeeks int
+
+// velocityCmd represents the velocity command
+var velocityCmd = &cobra.Command{
+	Use:   "velocity",
+	Short: "compare estimates with actual time and show completed points per week",
+	Long: `Show the tasks and story points completed per week, and how the estimated time of completed tasks compares
+with the time logged on them (with "task start"/"task stop" or "task focus").
+
+Estimate accuracy is also shown per category, to find the kinds of tasks that are habitually underestimated.
+Only tasks with a time estimate and some logged time count towards the accuracy.
+
+Example:
+
+task velocity --weeks 12`,
+	Args:        cobra.NoArgs,
+	Annotations: readOnly(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if velocityWeeks < 1 {
+			cmd.PrintErrln("--weeks must be at least 1")
+			return
+		}
+		weekStart := config.Get().WeekStartDay()
+		since := util.StartOfWeek(time.Now(), weekStart).AddDate(0, 0, -7*(velocityWeeks-1))
+		completed, err := tasks.GetCompletedTasks(since)
+		if err != nil {
+			cmd.PrintErrln("Error loading completed tasks:", err)
+			return
+		}
+		weeks, categories := tasks.Velocity(completed, since, weekStart)
+
+		fmt.Printf("Velocity, last %d week(s)\n\n", velocityWeeks)
+		fmt.Printf("%-10s  %5s  %6s  %9s  %7s  %8s\n", "Week of", "Done", "Points", "Estimated", "Actual", "Accuracy")
+		for _, w := range weeks {
+			fmt.Printf("%-10s  %5d  %6s  %9s  %7s  %8s\n", w.Start.Format("Jan 2"), w.Completed, formatPoints(w.Points),
+				formatOptionalDuration(w.Estimated), formatOptionalDuration(w.Actual), formatAccuracy(w.Estimated, w.Actual))
+		}
+
+		fmt.Println()
+		if len(categories) == 0 {
+			fmt.Println("No completed tasks with a time estimate and logged time yet.")
+			return
+		}
+		fmt.Printf("%s  %5s  %9s  %7s  %8s\n", util.PadRight("Category", 14), "Tasks", "Estimated", "Actual", "Accuracy")
+		for _, c := range categories {
+			name := c.Category
+			if name == "" {
+				name = "(none)"
+			}
+			verdict := ""
+			if c.Tasks >= minAccuracyTasks {
+				if c.Ratio() >= underestimateRatio {
+					verdict = "underestimated"
+				} else if c.Ratio() <= overestimateRatio {
+					verdict = "overestimated"
+				}
+			}
+			fmt.Printf("%s  %5d  %9s  %7s  %8s  %s\n", util.PadRight(util.Truncate(name, 14), 14), c.Tasks, util.FormatDuration(c.Estimated),
+				util.FormatDuration(c.Actual), formatAccuracy(c.Estimated, c.Actual), verdict)
+		}
+	},
+}
+
+func formatPoints(p float64) string {
+	if p == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+func formatOptionalDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return util.FormatDuration(d)
+}
+
+// formatAccuracy shows the actual time as a percentage of the estimate, e.g. 150% means it took half again as long
+func formatAccuracy(estimated, actual time.Duration) string {
+	if estimated == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(actual)/float64(estimated)*100)
+}
+
+func init() {
+	rootCmd.AddCommand(velocityCmd)
+	velocityCmd.Flags().IntVarP(&velocityWeeks, "weeks", "n", 8, "number of weeks to show, including this one")
+}
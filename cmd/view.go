@@ -8,21 +8,33 @@ import (
 
 // viewCmd represents the view command
 var viewCmd = &cobra.Command{
-	Use:   "view",
+	Use:   "view <task>",
 	Short: "view the details of a single task",
 	Long: `Launch a TUI application to view the details of a single task, such as description, notes, etc.
+Notes can be added, edited and deleted from the TUI.
 	
+The task can be given by its ID or by words from its title.
+
 Example:
 
-task view 9bf4`,
+task view 9bf4
+task view "deploy fix"`,
+	// the TUI opens the database itself, only while reading or saving notes
+	Annotations: noDatabase(),
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			cmd.PrintErrln("task ID required")
+			return
 		}
-		taskID := args[0]
-		err := taskui.RunUI(taskID)
+		ws := resolveWorkspace()
+		// the database is closed again before the picker or the TUI opens, so other commands aren't kept waiting
+		taskID, err := resolveTaskIDInWorkspace(ws, args[0])
 		if err != nil {
 			cmd.PrintErrln(err)
+			return
+		}
+		if err := taskui.RunUI(ws, taskID); err != nil {
+			cmd.PrintErrln(err)
 		}
 	},
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/webbben/task/internal/completions"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/util"
+)
+
+// workspaceCmd represents the workspace command
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage workspaces",
+	Long: `Manage workspaces. Each workspace has its own separate task database, e.g. for keeping work and home tasks apart.
+
+The workspace used by a command is chosen by (in order of priority): the --workspace flag, the TASK_WORKSPACE env var,
+the workspace selected with "task workspace switch" (the "workspace" config value), and finally the "default" workspace.
+
+Example usage:
+
+# create a new workspace and start using it
+task workspace create home
+task workspace switch home
+
+# add a task to a specific workspace without switching
+task add "pay rent" -w home`,
+	Annotations: noDatabase(),
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List all workspaces",
+	Args:        cobra.NoArgs,
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		names, err := storage.ListWorkspaces()
+		if err != nil {
+			cmd.PrintErrln("Error listing workspaces:", err)
+			return
+		}
+		current := resolveWorkspace()
+		for _, name := range names {
+			if name == current {
+				fmt.Println("* " + name)
+			} else {
+				fmt.Println("  " + name)
+			}
+		}
+	},
+}
+
+var workspaceCreateCmd = &cobra.Command{
+	Use:         "create <name>",
+	Short:       "Create a new workspace",
+	Args:        cobra.ExactArgs(1),
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := storage.CreateWorkspace(args[0]); err != nil {
+			cmd.PrintErrln("Error creating workspace:", err)
+			return
+		}
+		fmt.Printf("Created workspace %s\n", args[0])
+	},
+}
+
+var workspaceRenameCmd = &cobra.Command{
+	Use:         "rename <old-name> <new-name>",
+	Short:       "Rename a workspace",
+	Args:        cobra.ExactArgs(2),
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range args {
+			if err := storage.ValidateWorkspaceName(name); err != nil {
+				cmd.PrintErrln("Error renaming workspace:", err)
+				return
+			}
+		}
+		if err := storage.RenameWorkspace(args[0], args[1]); err != nil {
+			cmd.PrintErrln("Error renaming workspace:", err)
+			return
+		}
+		// keep using the workspace if it was the one switched to
+		if switchedWorkspace() == args[0] {
+			if err := config.Set("workspace", args[1]); err != nil {
+				cmd.PrintErrln("Error updating workspace in config:", err)
+			}
+		}
+		fmt.Printf("Renamed workspace %s to %s\n", args[0], args[1])
+	},
+}
+
+var workspaceDeleteCmd = &cobra.Command{
+	Use:         "delete <name>",
+	Short:       "Delete a workspace and all of its tasks",
+	Args:        cobra.ExactArgs(1),
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := storage.ValidateWorkspaceName(name); err != nil {
+			cmd.PrintErrln("Error deleting workspace:", err)
+			return
+		}
+		if !util.Confirm(fmt.Sprintf("Delete workspace %s and all of its tasks?", name)) {
+			return
+		}
+		if err := storage.DeleteWorkspace(name); err != nil {
+			cmd.PrintErrln("Error deleting workspace:", err)
+			return
+		}
+		if switchedWorkspace() == name {
+			if err := config.Set("workspace", ""); err != nil {
+				cmd.PrintErrln("Error updating workspace in config:", err)
+			}
+		}
+		fmt.Printf("Deleted workspace %s\n", name)
+	},
+}
+
+var workspaceSwitchCmd = &cobra.Command{
+	Use:         "switch <name>",
+	Short:       "Switch the workspace used by default",
+	Args:        cobra.ExactArgs(1),
+	Annotations: noDatabase(),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := storage.ValidateWorkspaceName(name); err != nil {
+			cmd.PrintErrln("Error switching workspace:", err)
+			return
+		}
+		if !storage.WorkspaceExists(name) {
+			cmd.PrintErrf("Error switching workspace: workspace %q does not exist\n", name)
+			return
+		}
+		if name == storage.DefaultWorkspace {
+			name = ""
+		}
+		if err := config.Set("workspace", name); err != nil {
+			cmd.PrintErrln("Error switching workspace:", err)
+			return
+		}
+		fmt.Printf("Switched to workspace %s\n", args[0])
+	},
+}
+
+// switchedWorkspace returns the workspace set in the config file by "task workspace switch"
+func switchedWorkspace() string {
+	c, err := config.LoadFile()
+	if err != nil {
+		return ""
+	}
+	return c.Workspace
+}
+
+func init() {
+	workspaceCompletion := func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, _ := storage.ListWorkspaces()
+		return completions.MatchFromListCompletionFn(toComplete, names, cmd)
+	}
+	workspaceRenameCmd.ValidArgsFunction = workspaceCompletion
+	workspaceDeleteCmd.ValidArgsFunction = workspaceCompletion
+	workspaceSwitchCmd.ValidArgsFunction = workspaceCompletion
+	rootCmd.RegisterFlagCompletionFunc("workspace", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return workspaceCompletion(cmd, nil, toComplete)
+	})
+
+	workspaceCmd.AddCommand(workspaceListCmd, workspaceCreateCmd, workspaceRenameCmd, workspaceDeleteCmd, workspaceSwitchCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
@@ -0,0 +1,103 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/webbben/task/internal/config"
+)
+
+// Calendar knows which days are working days: the working days of the week, minus holidays
+type Calendar struct {
+	workDays [7]bool
+	// holidays maps YYYY-MM-DD dates to the name of the holiday
+	holidays map[string]string
+}
+
+// New creates a calendar with the given working days of the week and holidays (YYYY-MM-DD dates mapped to names)
+func New(workDays []time.Weekday, holidays map[string]string) Calendar {
+	c := Calendar{holidays: holidays}
+	for _, d := range workDays {
+		c.workDays[d] = true
+	}
+	return c
+}
+
+// Standard is a Monday to Friday calendar without holidays
+func Standard() Calendar {
+	return New([]time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, nil)
+}
+
+// FromConfig creates a calendar from the work_days and holidays settings
+func FromConfig() Calendar {
+	c := config.Get()
+	days := c.WorkWeekdays()
+	if len(days) == 0 {
+		return Standard()
+	}
+	return New(days, c.Holidays)
+}
+
+// Holiday returns the name of the holiday on the day of t, if it's a holiday
+func (c Calendar) Holiday(t time.Time) (string, bool) {
+	name, ok := c.holidays[t.Format(time.DateOnly)]
+	return name, ok
+}
+
+// IsWorkDay returns true if the day of t is a working day that isn't a holiday
+func (c Calendar) IsWorkDay(t time.Time) bool {
+	if !c.workDays[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// AddWorkDays moves n working days forward from t (or back, if n is negative), skipping weekends and holidays.
+// The time of day is kept. With n = 0, t is returned even if it isn't a working day.
+func (c Calendar) AddWorkDays(t time.Time, n int) time.Time {
+	if !c.hasWorkDays() {
+		return t.AddDate(0, 0, n)
+	}
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if c.IsWorkDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// WorkDaysBetween counts the working days after the day of from, up to and including the day of to.
+// It's negative if to is before from.
+func (c Calendar) WorkDaysBetween(from, to time.Time) int {
+	from = startOfDay(from)
+	to = startOfDay(to)
+	sign := 1
+	if to.Before(from) {
+		from, to, sign = to, from, -1
+	}
+	n := 0
+	for d := from.AddDate(0, 0, 1); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkDay(d) {
+			n++
+		}
+	}
+	return sign * n
+}
+
+func (c Calendar) hasWorkDays() bool {
+	for _, ok := range c.workDays {
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
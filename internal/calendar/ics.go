@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Holiday is a day off read from an iCalendar file
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// ParseICS reads the events of an iCalendar (.ics) file as holidays. An event that spans several days gives a holiday per day.
+// Recurrence rules aren't expanded, so a recurring event only counts on its first date.
+func ParseICS(r io.Reader) ([]Holiday, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var holidays []Holiday
+	var inEvent bool
+	var start, end time.Time
+	var name string
+	for i, line := range lines {
+		prop, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// parameters like ;VALUE=DATE come after the property name
+		prop, _, _ = strings.Cut(strings.ToUpper(prop), ";")
+
+		switch {
+		case prop == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end, name = time.Time{}, time.Time{}, ""
+		case !inEvent:
+		case prop == "DTSTART" || prop == "DTEND":
+			d, err := parseICSDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if prop == "DTSTART" {
+				start = d
+			} else {
+				end = d
+			}
+		case prop == "SUMMARY":
+			name = unescapeText(value)
+		case prop == "END" && value == "VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				continue
+			}
+			// the end date of an all day event is the day after it ends
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				holidays = append(holidays, Holiday{Date: d, Name: name})
+			}
+		}
+	}
+	return holidays, nil
+}
+
+// unfoldLines splits the file into lines, joining long lines that were folded onto the next line (which then starts with a space or tab)
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSDate parses a DATE (20261225) or DATE-TIME (20261225T090000[Z]) value, keeping only the date
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	d, err := time.ParseInLocation("20060102", value[:8], time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return d, nil
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -1,15 +1,12 @@
 package completions
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/webbben/task/internal/storage"
-	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/tasks"
 	"github.com/webbben/task/internal/util"
-	"go.etcd.io/bbolt"
 )
 
 type TaskPreview struct {
@@ -17,44 +14,19 @@ type TaskPreview struct {
 	Title string
 }
 
-// CompleteTaskID finds all tasks that have an ID starting with the given string.
+// CompleteTaskID finds the active tasks that s could refer to: the tasks with an ID starting with s, or else the tasks whose title matches it.
+// shells only offer completions that start with what was typed, so title matches are only shown by shells that don't filter them.
 func CompleteTaskID(s string) ([]TaskPreview, error) {
-	var taskPreviews []TaskPreview
-
-	if len(s) > 8 {
-		return taskPreviews, errors.New("given ID prefix is too long")
+	all, err := tasks.GetAllTasks()
+	if err != nil {
+		return nil, err
 	}
-
-	db := storage.DB()
-	if db == nil {
-		return taskPreviews, errors.New("failed to get tasks db")
+	matches, _ := tasks.MatchTasks(s, all)
+	taskPreviews := make([]TaskPreview, len(matches))
+	for i, t := range matches {
+		taskPreviews[i] = TaskPreview{ID: t.ID, Title: t.Title}
 	}
-
-	err := db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket([]byte(storage.ACTIVE_BUCKET))
-		if b == nil {
-			return nil
-		}
-
-		return b.ForEach(func(k, v []byte) error {
-			id := string(k)
-
-			if id[:len(s)] == s {
-				var data types.Task
-				err := json.Unmarshal(v, &data)
-				if err != nil {
-					return errors.New("failed to unmarshal task data")
-				}
-				taskPreviews = append(taskPreviews, TaskPreview{
-					ID:    id,
-					Title: data.Title,
-				})
-			}
-			return nil
-		})
-	})
-
-	return taskPreviews, err
+	return taskPreviews, nil
 }
 
 // MatchFromListCompletionFn is a completion function for a given list of possible options.
@@ -101,3 +73,29 @@ func TaskIDCompletionFn(firstArgOnly bool) func(cmd *cobra.Command, args []strin
 		return matches, cobra.ShellCompDirectiveNoFileComp
 	}
 }
+
+// NoteIDCompletionFn provides a completion function for commands that take a task ID followed by a note ID
+func NoteIDCompletionFn() func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	taskIDFn := TaskIDCompletionFn(true)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return taskIDFn(cmd, args, toComplete)
+		case 1:
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		task, err := tasks.ResolveTask(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var matches []string
+		for _, note := range task.Notes {
+			if strings.HasPrefix(note.ID, toComplete) {
+				matches = append(matches, fmt.Sprintf("%s\t(%s: %s)", note.ID, note.Name(), util.Truncate(note.Content, 20)))
+			}
+		}
+		return matches, cobra.ShellCompDirectiveNoFileComp
+	}
+}
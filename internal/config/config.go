@@ -0,0 +1,661 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/webbben/task/internal/storage"
+)
+
+const (
+	CONFIG_FILE = "config.toml"
+
+	// envPrefix is the prefix for env vars that override config values, e.g. TASK_EDITOR overrides "editor"
+	envPrefix = "TASK_"
+)
+
+// Config holds all the user configurable settings
+type Config struct {
+	// DefaultDue is the due date used for new tasks when none is given, as a relative date (e.g. "0d", "1w")
+	DefaultDue string `toml:"default_due"`
+	// DateFormat is the Go time layout used to show due dates in the task table
+	DateFormat string `toml:"date_format"`
+	// WeekStart is the first day of the week, e.g. "monday"
+	WeekStart string `toml:"week_start"`
+	// WorkDays are the days of the week that count as working days, e.g. "mon"
+	WorkDays []string `toml:"work_days"`
+	// Holidays are days off, as YYYY-MM-DD dates mapped to the name of the holiday (which can be empty)
+	Holidays map[string]string `toml:"holidays"`
+	// Editor is the command used to edit notes. If empty, $EDITOR is used, then vi.
+	Editor string `toml:"editor"`
+	// Workspace is the workspace used when none is given with the --workspace flag
+	Workspace string `toml:"workspace"`
+	// Columns are the columns shown in the task table, in order
+	Columns []string `toml:"columns"`
+	// ColumnWidths overrides the width of individual columns in the task table
+	ColumnWidths map[string]int `toml:"column_widths"`
+	// Theme sets the colors used in the task table
+	Theme Theme `toml:"theme"`
+	// Focus sets the intervals used by "task focus"
+	Focus Focus `toml:"focus"`
+	// Urgency sets the weights used to score how urgent each task is
+	Urgency Urgency `toml:"urgency"`
+	// Reminders sets how "task daemon" sends reminders
+	Reminders Reminders `toml:"reminders"`
+}
+
+// Theme holds the color specs for each colored element. See ParseColor for the format.
+type Theme struct {
+	VeryLate   string `toml:"very_late"`
+	Late       string `toml:"late"`
+	Today      string `toml:"today"`
+	Tomorrow   string `toml:"tomorrow"`
+	Complete   string `toml:"complete"`
+	InProgress string `toml:"in_progress"`
+	Border     string `toml:"border"`
+}
+
+// Focus holds the pomodoro intervals, as Go durations (e.g. "25m")
+type Focus struct {
+	Work      string `toml:"work"`
+	Break     string `toml:"break"`
+	LongBreak string `toml:"long_break"`
+	// LongBreakEvery is the number of work intervals between long breaks
+	LongBreakEvery int `toml:"long_break_every"`
+}
+
+// Urgency holds the weights of the urgency score. See tasks.Urgency for how they're used.
+type Urgency struct {
+	// Overdue is added for each day a task is overdue
+	Overdue float64 `toml:"overdue"`
+	// DueSoon is added for tasks due today, and half of it for tasks due tomorrow
+	DueSoon float64 `toml:"due_soon"`
+	// Priority is multiplied by the task's priority
+	Priority float64 `toml:"priority"`
+	// Age is added for each day since the task was last updated
+	Age float64 `toml:"age"`
+	// InProgress is added for tasks that are in progress
+	InProgress float64 `toml:"in_progress"`
+	// Tags are added for each tag the task has
+	Tags map[string]float64 `toml:"tags"`
+	// Blocked is added (usually as a penalty) for tasks with the BlockedTag
+	Blocked    float64 `toml:"blocked"`
+	BlockedTag string  `toml:"blocked_tag"`
+	// TodoMin is the lowest score shown by "task list --todo"
+	TodoMin float64 `toml:"todo_min"`
+}
+
+// Reminders holds the settings of "task daemon"
+type Reminders struct {
+	// Notifiers are where reminders are sent: log (standard output), desktop (notify-send) and webhook
+	Notifiers []string `toml:"notifiers"`
+	// WebhookURL is where the webhook notifier posts reminders, as JSON
+	WebhookURL string `toml:"webhook_url"`
+	// Before is how long before a task due at a time of day it's reminded about, e.g. "30m"
+	Before string `toml:"before"`
+}
+
+// NotifierNames are the notifiers that can be used for reminders
+var NotifierNames = []string{"log", "desktop", "webhook"}
+
+// BeforeDuration returns the parsed Before setting
+func (r Reminders) BeforeDuration() time.Duration {
+	d, err := time.ParseDuration(r.Before)
+	if err != nil {
+		return 30 * time.Minute
+	}
+	return d
+}
+
+// Durations returns the parsed work, break and long break intervals
+func (f Focus) Durations() (work, brk, longBreak time.Duration) {
+	parse := func(s string, fallback time.Duration) time.Duration {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return fallback
+		}
+		return d
+	}
+	return parse(f.Work, 25*time.Minute), parse(f.Break, 5*time.Minute), parse(f.LongBreak, 15*time.Minute)
+}
+
+// Default returns the default configuration, which matches how things worked before there was a config file
+func Default() Config {
+	return Config{
+		DefaultDue: "0d",
+		DateFormat: "1-2",
+		WeekStart:  "monday",
+		WorkDays:   []string{"mon", "tue", "wed", "thu", "fri"},
+		Columns:    []string{"id", "title", "due", "status", "pr", "upd"},
+		Theme: Theme{
+			VeryLate:   "bg-red",
+			Late:       "fg-hi-red",
+			Today:      "fg-hi-yellow",
+			Tomorrow:   "fg-cyan",
+			Complete:   "bg-green,fg-black",
+			InProgress: "fg-cyan",
+			Border:     "fg-hi-black",
+		},
+		Focus: Focus{
+			Work:           "25m",
+			Break:          "5m",
+			LongBreak:      "15m",
+			LongBreakEvery: 4,
+		},
+		Urgency: Urgency{
+			Overdue:    2,
+			DueSoon:    4,
+			Priority:   1,
+			Age:        0.1,
+			InProgress: 3,
+			Blocked:    -5,
+			BlockedTag: "blocked",
+			TodoMin:    2,
+		},
+		Reminders: Reminders{
+			Notifiers: []string{"log"},
+			Before:    "30m",
+		},
+	}
+}
+
+var (
+	loaded    Config
+	loadOnce  sync.Once
+	relDateRe = regexp.MustCompile(`^-?\d+(bd|[dwmy])$`)
+)
+
+// Path returns the path of the config file
+func Path() string {
+	return filepath.Join(storage.ConfigPathUnix(), CONFIG_FILE)
+}
+
+// Get returns the current configuration: the defaults, overridden by the config file, overridden by env vars.
+//
+// If the config file is invalid, a warning is logged and the defaults are used instead.
+func Get() Config {
+	loadOnce.Do(func() {
+		c, err := Load()
+		if err != nil {
+			log.Printf("invalid config file %s: %v (using defaults)", Path(), err)
+			c = Default()
+			applyEnv(&c)
+		}
+		loaded = c
+	})
+	return loaded
+}
+
+// Load reads the config file and env var overrides
+func Load() (Config, error) {
+	c, err := LoadFile()
+	if err != nil {
+		return c, err
+	}
+	if err := applyEnv(&c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// LoadFile reads the config file, without applying env var overrides
+func LoadFile() (Config, error) {
+	c := Default()
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, err
+	}
+	if _, err := toml.Decode(string(data), &c); err != nil {
+		return c, err
+	}
+	return c, c.validate()
+}
+
+// Save writes the given config to the config file
+func Save(c Config) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return err
+	}
+	return os.WriteFile(Path(), buf.Bytes(), 0600)
+}
+
+func (c Config) validate() error {
+	for _, s := range settings {
+		if err := s.set(&c, s.get(c)); err != nil {
+			return fmt.Errorf("%s: %w", s.Key, err)
+		}
+	}
+	return nil
+}
+
+// EnvVar returns the name of the env var that overrides the given key
+func EnvVar(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func applyEnv(c *Config) error {
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(EnvVar(s.Key)); ok {
+			if err := s.set(c, v); err != nil {
+				return fmt.Errorf("$%s: %w", EnvVar(s.Key), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Setting is a single config value that can be read and written with "task config"
+type Setting struct {
+	Key         string
+	Description string
+	get         func(c Config) string
+	set         func(c *Config, v string) error
+}
+
+var settings = []Setting{
+	{
+		Key:         "default_due",
+		Description: "due date for new tasks when none is given, relative to today (e.g. 0d, 2d, 1w)",
+		get:         func(c Config) string { return c.DefaultDue },
+		set: func(c *Config, v string) error {
+			if !relDateRe.MatchString(v) {
+				return errors.New("must be a number followed by d, bd, w, m or y")
+			}
+			c.DefaultDue = v
+			return nil
+		},
+	},
+	{
+		Key:         "date_format",
+		Description: "Go time layout for due dates in the task table (e.g. 1-2, 01/02, Jan 2)",
+		get:         func(c Config) string { return c.DateFormat },
+		set: func(c *Config, v string) error {
+			if v == "" {
+				return errors.New("must not be empty")
+			}
+			c.DateFormat = v
+			return nil
+		},
+	},
+	{
+		Key:         "week_start",
+		Description: "first day of the week (e.g. monday, sunday)",
+		get:         func(c Config) string { return c.WeekStart },
+		set: func(c *Config, v string) error {
+			d, err := parseWeekday(v)
+			if err != nil {
+				return err
+			}
+			c.WeekStart = strings.ToLower(d.String())
+			return nil
+		},
+	},
+	{
+		Key:         "work_days",
+		Description: "comma separated working days, used by business day dates like 3bd",
+		get:         func(c Config) string { return strings.Join(c.WorkDays, ",") },
+		set: func(c *Config, v string) error {
+			days := splitList(v)
+			if len(days) == 0 {
+				return errors.New("at least one working day is needed")
+			}
+			for i, day := range days {
+				d, err := parseWeekday(day)
+				if err != nil {
+					return err
+				}
+				days[i] = strings.ToLower(d.String()[:3])
+			}
+			c.WorkDays = days
+			return nil
+		},
+	},
+	{
+		Key:         "holidays",
+		Description: "comma separated days off, as YYYY-MM-DD or YYYY-MM-DD=name (see task cal import)",
+		get: func(c Config) string {
+			days := make([]string, 0, len(c.Holidays))
+			for day, name := range c.Holidays {
+				if name != "" {
+					day += "=" + name
+				}
+				days = append(days, day)
+			}
+			sort.Strings(days)
+			return strings.Join(days, ",")
+		},
+		set: func(c *Config, v string) error {
+			holidays := make(map[string]string)
+			for _, pair := range splitList(v) {
+				day, name, _ := strings.Cut(pair, "=")
+				day = strings.TrimSpace(day)
+				if _, err := time.Parse(time.DateOnly, day); err != nil {
+					return fmt.Errorf("invalid date %q: expected YYYY-MM-DD", day)
+				}
+				holidays[day] = strings.TrimSpace(name)
+			}
+			c.Holidays = holidays
+			return nil
+		},
+	},
+	{
+		Key:         "editor",
+		Description: "command used to edit notes (defaults to $EDITOR, then vi)",
+		get:         func(c Config) string { return c.Editor },
+		set:         func(c *Config, v string) error { c.Editor = v; return nil },
+	},
+	{
+		Key:         "workspace",
+		Description: "workspace used when none is given with --workspace",
+		get:         func(c Config) string { return c.Workspace },
+		set: func(c *Config, v string) error {
+			if v != "" {
+				if err := storage.ValidateWorkspaceName(v); err != nil {
+					return err
+				}
+			}
+			c.Workspace = v
+			return nil
+		},
+	},
+	{
+		Key:         "columns",
+		Description: "comma separated columns shown in the task table",
+		get:         func(c Config) string { return strings.Join(c.Columns, ",") },
+		set: func(c *Config, v string) error {
+			cols := splitList(v)
+			if len(cols) == 0 {
+				return errors.New("at least one column is required")
+			}
+			c.Columns = cols
+			return nil
+		},
+	},
+	{
+		Key:         "column_widths",
+		Description: "comma separated column widths, e.g. title=30,cat=10",
+		get: func(c Config) string {
+			pairs := make([]string, 0, len(c.ColumnWidths))
+			for col, w := range c.ColumnWidths {
+				pairs = append(pairs, fmt.Sprintf("%s=%d", col, w))
+			}
+			sort.Strings(pairs)
+			return strings.Join(pairs, ",")
+		},
+		set: func(c *Config, v string) error {
+			widths := make(map[string]int)
+			for _, pair := range splitList(v) {
+				col, w, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("expected column=width, got %q", pair)
+				}
+				n, err := strconv.Atoi(w)
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid width for column %s: %q", col, w)
+				}
+				widths[strings.TrimSpace(col)] = n
+			}
+			c.ColumnWidths = widths
+			return nil
+		},
+	},
+	themeSetting("theme.very_late", "color of due dates more than two days late", func(t *Theme) *string { return &t.VeryLate }),
+	themeSetting("theme.late", "color of late due dates", func(t *Theme) *string { return &t.Late }),
+	themeSetting("theme.today", "color of due dates today", func(t *Theme) *string { return &t.Today }),
+	themeSetting("theme.tomorrow", "color of due dates tomorrow", func(t *Theme) *string { return &t.Tomorrow }),
+	themeSetting("theme.complete", "color of the complete status", func(t *Theme) *string { return &t.Complete }),
+	themeSetting("theme.in_progress", "color of the in progress status", func(t *Theme) *string { return &t.InProgress }),
+	themeSetting("theme.border", "color of the table borders", func(t *Theme) *string { return &t.Border }),
+	focusSetting("focus.work", "length of a focus work interval", func(f *Focus) *string { return &f.Work }),
+	focusSetting("focus.break", "length of a short focus break", func(f *Focus) *string { return &f.Break }),
+	focusSetting("focus.long_break", "length of a long focus break", func(f *Focus) *string { return &f.LongBreak }),
+	{
+		Key:         "focus.long_break_every",
+		Description: "number of work intervals before a long break",
+		get:         func(c Config) string { return strconv.Itoa(c.Focus.LongBreakEvery) },
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number %q: expected a positive whole number", v)
+			}
+			c.Focus.LongBreakEvery = n
+			return nil
+		},
+	},
+	urgencySetting("urgency.overdue", "urgency added per day a task is overdue", func(u *Urgency) *float64 { return &u.Overdue }),
+	urgencySetting("urgency.due_soon", "urgency added to tasks due today (half for tomorrow)", func(u *Urgency) *float64 { return &u.DueSoon }),
+	urgencySetting("urgency.priority", "urgency added per priority level", func(u *Urgency) *float64 { return &u.Priority }),
+	urgencySetting("urgency.age", "urgency added per day since a task was last updated", func(u *Urgency) *float64 { return &u.Age }),
+	urgencySetting("urgency.in_progress", "urgency added to tasks in progress", func(u *Urgency) *float64 { return &u.InProgress }),
+	urgencySetting("urgency.blocked", "urgency added to blocked tasks (usually negative)", func(u *Urgency) *float64 { return &u.Blocked }),
+	urgencySetting("urgency.todo_min", "lowest urgency shown by task list --todo", func(u *Urgency) *float64 { return &u.TodoMin }),
+	{
+		Key:         "reminders.notifiers",
+		Description: "comma separated notifiers used by task daemon: log, desktop, webhook",
+		get:         func(c Config) string { return strings.Join(c.Reminders.Notifiers, ",") },
+		set: func(c *Config, v string) error {
+			names := splitList(v)
+			for _, name := range names {
+				if !slices.Contains(NotifierNames, name) {
+					return fmt.Errorf("unknown notifier %q: expected one of %s", name, strings.Join(NotifierNames, ", "))
+				}
+			}
+			c.Reminders.Notifiers = names
+			return nil
+		},
+	},
+	{
+		Key:         "reminders.webhook_url",
+		Description: "URL the webhook notifier posts reminders to",
+		get:         func(c Config) string { return c.Reminders.WebhookURL },
+		set: func(c *Config, v string) error {
+			if v != "" {
+				u, err := url.Parse(v)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+					return fmt.Errorf("invalid URL %q: expected an http or https URL", v)
+				}
+			}
+			c.Reminders.WebhookURL = v
+			return nil
+		},
+	},
+	{
+		Key:         "reminders.before",
+		Description: "how long before a task due at a time of day to remind about it (e.g. 30m)",
+		get:         func(c Config) string { return c.Reminders.Before },
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return fmt.Errorf("invalid duration %q: expected e.g. 30m", v)
+			}
+			c.Reminders.Before = v
+			return nil
+		},
+	},
+	{
+		Key:         "urgency.blocked_tag",
+		Description: "tag that marks a task as blocked",
+		get:         func(c Config) string { return c.Urgency.BlockedTag },
+		set: func(c *Config, v string) error {
+			c.Urgency.BlockedTag = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		Key:         "urgency.tags",
+		Description: "comma separated urgency per tag, e.g. urgent=5,someday=-2",
+		get: func(c Config) string {
+			pairs := make([]string, 0, len(c.Urgency.Tags))
+			for tag, w := range c.Urgency.Tags {
+				pairs = append(pairs, tag+"="+strconv.FormatFloat(w, 'f', -1, 64))
+			}
+			sort.Strings(pairs)
+			return strings.Join(pairs, ",")
+		},
+		set: func(c *Config, v string) error {
+			weights := make(map[string]float64)
+			for _, pair := range splitList(v) {
+				tag, w, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("expected tag=weight, got %q", pair)
+				}
+				n, err := strconv.ParseFloat(strings.TrimSpace(w), 64)
+				if err != nil {
+					return fmt.Errorf("invalid weight for tag %s: %q", tag, w)
+				}
+				weights[strings.TrimSpace(tag)] = n
+			}
+			c.Urgency.Tags = weights
+			return nil
+		},
+	},
+}
+
+func themeSetting(key, description string, field func(t *Theme) *string) Setting {
+	return Setting{
+		Key:         key,
+		Description: description + " (e.g. fg-red, bg-green,fg-black, bold, none)",
+		get:         func(c Config) string { return *field(&c.Theme) },
+		set: func(c *Config, v string) error {
+			if _, err := ParseColor(v); err != nil {
+				return err
+			}
+			*field(&c.Theme) = v
+			return nil
+		},
+	}
+}
+
+func focusSetting(key, description string, field func(f *Focus) *string) Setting {
+	return Setting{
+		Key:         key,
+		Description: description + " (e.g. 25m, 1h)",
+		get:         func(c Config) string { return *field(&c.Focus) },
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid duration %q: expected e.g. 25m", v)
+			}
+			*field(&c.Focus) = v
+			return nil
+		},
+	}
+}
+
+func urgencySetting(key, description string, field func(u *Urgency) *float64) Setting {
+	return Setting{
+		Key:         key,
+		Description: description,
+		get:         func(c Config) string { return strconv.FormatFloat(*field(&c.Urgency), 'f', -1, 64) },
+		set: func(c *Config, v string) error {
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", v)
+			}
+			*field(&c.Urgency) = n
+			return nil
+		},
+	}
+}
+
+// Settings returns all the settings that can be configured
+func Settings() []Setting {
+	return settings
+}
+
+func findSetting(key string) (Setting, error) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, nil
+		}
+	}
+	return Setting{}, fmt.Errorf("unknown config key: %s", key)
+}
+
+// Value returns the current value of the given key in the config
+func (c Config) Value(key string) (string, error) {
+	s, err := findSetting(key)
+	if err != nil {
+		return "", err
+	}
+	return s.get(c), nil
+}
+
+// Set parses and validates the given value and sets it on the config file. Env var overrides are not affected.
+func Set(key, value string) error {
+	s, err := findSetting(key)
+	if err != nil {
+		return err
+	}
+	c, err := LoadFile()
+	if err != nil {
+		return fmt.Errorf("failed to load config file: %w", err)
+	}
+	if err := s.set(&c, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return Save(c)
+}
+
+// WorkWeekdays returns the configured working days of the week
+func (c Config) WorkWeekdays() []time.Weekday {
+	days := make([]time.Weekday, 0, len(c.WorkDays))
+	for _, s := range c.WorkDays {
+		if d, err := parseWeekday(s); err == nil {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// WeekStartDay returns the configured first day of the week
+func (c Config) WeekStartDay() time.Weekday {
+	d, err := parseWeekday(c.WeekStart)
+	if err != nil {
+		return time.Monday
+	}
+	return d
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	if len(s) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), s) {
+				return d, nil
+			}
+		}
+	}
+	return time.Monday, fmt.Errorf("invalid day of the week: %q", s)
+}
+
+func splitList(v string) []string {
+	out := make([]string, 0)
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
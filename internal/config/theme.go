@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var colorNames = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+var styleNames = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"faint":     color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+	"reverse":   color.ReverseVideo,
+}
+
+// ParseColor parses a color spec, which is a comma separated list of attributes:
+//
+//	fg-<color>, fg-hi-<color>, bg-<color>, bg-hi-<color>, bold, faint, italic, underline, reverse
+//
+// colors are black, red, green, yellow, blue, magenta, cyan and white. "none" (or an empty spec) means no color.
+func ParseColor(spec string) (*color.Color, error) {
+	attrs := make([]color.Attribute, 0)
+	for _, part := range splitList(strings.ToLower(spec)) {
+		if part == "none" {
+			continue
+		}
+		if a, ok := styleNames[part]; ok {
+			attrs = append(attrs, a)
+			continue
+		}
+		a, err := parseColorAttr(part)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, a)
+	}
+	c := color.New(attrs...)
+	if len(attrs) == 0 {
+		// otherwise an empty escape sequence would still be written
+		c.DisableColor()
+	}
+	return c, nil
+}
+
+func parseColorAttr(s string) (color.Attribute, error) {
+	ground, name, ok := strings.Cut(s, "-")
+	if !ok || (ground != "fg" && ground != "bg") {
+		return 0, fmt.Errorf("invalid color %q: expected fg-<color> or bg-<color>", s)
+	}
+	hi := false
+	if after, found := strings.CutPrefix(name, "hi-"); found {
+		hi = true
+		name = after
+	}
+	a, ok := colorNames[name]
+	if !ok {
+		return 0, fmt.Errorf("invalid color %q: unknown color %s", s, name)
+	}
+	// the color attributes are laid out so that the background and hi-intensity variants are at fixed offsets
+	if hi {
+		a += color.FgHiBlack - color.FgBlack
+	}
+	if ground == "bg" {
+		a += color.BgBlack - color.FgBlack
+	}
+	return a, nil
+}
+
+// ColorOrNone parses the given color spec, falling back to no color if it's invalid
+func ColorOrNone(spec string) *color.Color {
+	c, err := ParseColor(spec)
+	if err != nil {
+		c, _ = ParseColor("none")
+	}
+	return c
+}
@@ -0,0 +1,31 @@
+package dates
+
+import (
+	"time"
+
+	"github.com/webbben/task/internal/calendar"
+	"github.com/webbben/task/internal/config"
+)
+
+// ParseDueDate parses the due date string and returns a time.Time. See Parser.Parse for the formats it understands.
+//
+// An empty string means the default due date from the config.
+func ParseDueDate(dueDate string) (time.Time, error) {
+	res, err := ParseDue(dueDate)
+	return res.Time, err
+}
+
+// ParseDue is like ParseDueDate, but returns the whole result, so the caller can report any ambiguity
+func ParseDue(dueDate string) (Result, error) {
+	if dueDate == "" {
+		dueDate = config.Get().DefaultDue
+	}
+	return Parse(dueDate)
+}
+
+// Parse parses s relative to the current time, using the configured first day of the week and working days
+func Parse(s string) (Result, error) {
+	cal := calendar.FromConfig()
+	p := Parser{Now: time.Now, WeekStart: config.Get().WeekStartDay(), Calendar: &cal}
+	return p.Parse(s)
+}
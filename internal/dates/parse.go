@@ -0,0 +1,400 @@
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/webbben/task/internal/calendar"
+)
+
+// Parser parses dates written the way people write them, like "tomorrow", "next fri 15:00", "in 3 days" or "nov 3".
+// Relative dates are calculated from the time returned by Now, so it can be replaced with a fixed clock.
+type Parser struct {
+	// Now returns the current time. Parsed dates use its location.
+	Now func() time.Time
+	// WeekStart is the first day of the week, used by "eow" and "next <weekday>"
+	WeekStart time.Weekday
+	// Calendar is used to count business days, like "3bd". If nil, Monday to Friday are the working days.
+	Calendar *calendar.Calendar
+}
+
+// Result is a parsed date
+type Result struct {
+	// Time is the parsed date. If no time of day was given, it's the start of the day.
+	Time time.Time
+	// HasTime is true if the input included a time of day, or was a relative time like "in 2 hours"
+	HasTime bool
+	// Ambiguity explains how the input could have been meant differently, and which reading was used.
+	// It's empty if the input was clear.
+	Ambiguity string
+}
+
+var (
+	isoDateRe   = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	slashDateRe = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+	clockRe     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	relativeRe  = regexp.MustCompile(`^([+-]?\d+)([a-z]*)$`)
+	dayNumRe    = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+// relativeUnit is a unit of a relative date, like "3 days". Exactly one field is set.
+type relativeUnit struct {
+	days, workDays, months, years int
+	dur                           time.Duration
+}
+
+var relativeUnits = map[string]relativeUnit{
+	"d": {days: 1}, "day": {days: 1}, "days": {days: 1},
+	"bd": {workDays: 1}, "bday": {workDays: 1}, "bdays": {workDays: 1},
+	"businessday": {workDays: 1}, "businessdays": {workDays: 1}, "workday": {workDays: 1}, "workdays": {workDays: 1},
+	"workingday": {workDays: 1}, "workingdays": {workDays: 1},
+	"w": {days: 7}, "wk": {days: 7}, "wks": {days: 7}, "week": {days: 7}, "weeks": {days: 7},
+	"m": {months: 1}, "mo": {months: 1}, "mos": {months: 1}, "month": {months: 1}, "months": {months: 1},
+	"y": {years: 1}, "yr": {years: 1}, "yrs": {years: 1}, "year": {years: 1}, "years": {years: 1},
+	"h": {dur: time.Hour}, "hr": {dur: time.Hour}, "hrs": {dur: time.Hour}, "hour": {dur: time.Hour}, "hours": {dur: time.Hour},
+	"min": {dur: time.Minute}, "mins": {dur: time.Minute}, "minute": {dur: time.Minute}, "minutes": {dur: time.Minute},
+}
+
+// Parse parses s, which can be:
+//
+//   - a named day: today, tomorrow, yesterday, eod, eow, eom, eoy (or "end of day", "end of week", ...)
+//   - a weekday: fri, "this fri" (today if it's Friday), "next fri" (Friday of next week)
+//   - a relative date: 3d, -1w, "in 3 days", "2 weeks ago", "next month", "in 2 hours" (d, w, m, y, h and min units)
+//   - business days, skipping weekends and holidays: 3bd, "in 3 business days", "2 workdays ago"
+//   - a date: 2026-11-03, 11/3, 11/3/2026, "nov 3", "3 nov", "nov 3 2026"
+//
+// Any of these but relative times in hours or minutes can be followed by a time of day, like "fri 15:00",
+// "tomorrow at 9am" or "2026-11-03T15:00". A time of day on its own means today.
+func (p Parser) Parse(s string) (Result, error) {
+	now := p.Now()
+	tokens := tokenize(s)
+	if len(tokens) == 0 {
+		return Result{}, fmt.Errorf("empty date")
+	}
+
+	tokens, clock, hasClock, err := splitClock(tokens)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var res Result
+	if len(tokens) == 0 {
+		res.Time = startOfDay(now)
+	} else if unit, n, ok := parseRelative(tokens); ok {
+		if unit.dur != 0 {
+			if hasClock {
+				return Result{}, fmt.Errorf("a time of day can't be given with a relative time in hours or minutes")
+			}
+			return Result{Time: now.Add(time.Duration(n) * unit.dur), HasTime: true}, nil
+		}
+		if unit.workDays != 0 {
+			res.Time = p.calendar().AddWorkDays(startOfDay(now), n)
+		} else {
+			res.Time = addDate(startOfDay(now), n*unit.years, n*unit.months, n*unit.days)
+		}
+	} else {
+		res, err = p.parseDay(tokens, now)
+		if err != nil {
+			return Result{}, fmt.Errorf("%w: %q", err, s)
+		}
+	}
+
+	if hasClock {
+		// the date's wall clock is set rather than adding the duration, which would be off by an hour on DST changes
+		y, m, d := res.Time.Date()
+		res.Time = time.Date(y, m, d, int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, res.Time.Location())
+		res.HasTime = true
+		if len(tokens) == 0 && res.Time.Before(now) {
+			clock := res.Time.Format("15:04")
+			res.Ambiguity = fmt.Sprintf("%s has already passed today; use \"tomorrow %s\" for tomorrow", clock, clock)
+		}
+	}
+	return res, nil
+}
+
+// tokenize lowercases s and splits it into words, joining "3 pm" into "3pm" and splitting ISO date times at the T
+func tokenize(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if (f == "am" || f == "pm") && len(tokens) > 0 {
+			tokens[len(tokens)-1] += f
+			continue
+		}
+		if date, clock, ok := strings.Cut(f, "t"); ok && isoDateRe.MatchString(date) {
+			tokens = append(tokens, date, clock)
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+// splitClock removes a time of day (and an "at" before it) from the end of tokens, and returns it as the time since midnight
+func splitClock(tokens []string) ([]string, time.Duration, bool, error) {
+	last := tokens[len(tokens)-1]
+	clock, ok, err := parseClock(last)
+	if err != nil || !ok {
+		return tokens, 0, false, err
+	}
+	tokens = tokens[:len(tokens)-1]
+	if len(tokens) > 0 && tokens[len(tokens)-1] == "at" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens, clock, true, nil
+}
+
+// parseClock parses a time of day like 15:00, 3pm or 3:30pm. A bare number isn't a time of day, since it could be am or pm.
+func parseClock(s string) (time.Duration, bool, error) {
+	switch s {
+	case "noon":
+		return 12 * time.Hour, true, nil
+	case "midnight":
+		return 0, true, nil
+	}
+	m := clockRe.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return 0, false, nil
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if minute > 59 {
+		return 0, false, fmt.Errorf("invalid time %q", s)
+	}
+	switch m[3] {
+	case "":
+		if hour > 23 {
+			return 0, false, fmt.Errorf("invalid time %q", s)
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return 0, false, fmt.Errorf("invalid time %q", s)
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true, nil
+}
+
+// parseRelative parses a relative date like 3d, "in 3 days", "3 days ago" or "next month", and returns its unit and how many of it
+func parseRelative(tokens []string) (relativeUnit, int, bool) {
+	if len(tokens) == 2 && tokens[0] == "next" {
+		if u, ok := relativeUnits[tokens[1]]; ok && u.days != 1 {
+			return u, 1, true
+		}
+		return relativeUnit{}, 0, false
+	}
+
+	sign := 1
+	if tokens[0] == "in" {
+		tokens = tokens[1:]
+	} else if tokens[len(tokens)-1] == "ago" {
+		sign = -1
+		tokens = tokens[:len(tokens)-1]
+	}
+	// "3 business days" is the same as "3businessdays"
+	if len(tokens) == 3 && (tokens[1] == "business" || tokens[1] == "work" || tokens[1] == "working") {
+		tokens = []string{tokens[0], tokens[1] + tokens[2]}
+	}
+	// "3 days" is the same as "3days"
+	if len(tokens) == 2 {
+		tokens = []string{tokens[0] + tokens[1]}
+	}
+	if len(tokens) != 1 {
+		return relativeUnit{}, 0, false
+	}
+	m := relativeRe.FindStringSubmatch(tokens[0])
+	if m == nil {
+		return relativeUnit{}, 0, false
+	}
+	u, ok := relativeUnits[m[2]]
+	if !ok {
+		return relativeUnit{}, 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return relativeUnit{}, 0, false
+	}
+	return u, sign * n, true
+}
+
+// parseDay parses everything but relative dates, and returns the start of the day
+func (p Parser) parseDay(tokens []string, now time.Time) (Result, error) {
+	today := startOfDay(now)
+	switch strings.Join(tokens, " ") {
+	case "today", "eod", "end of day":
+		return Result{Time: today}, nil
+	case "tomorrow", "tmr", "tmrw":
+		return Result{Time: today.AddDate(0, 0, 1)}, nil
+	case "yesterday":
+		return Result{Time: today.AddDate(0, 0, -1)}, nil
+	case "eow", "end of week":
+		return Result{Time: p.startOfWeek(today).AddDate(0, 0, 6)}, nil
+	case "eom", "end of month":
+		return Result{Time: time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location())}, nil
+	case "eoy", "end of year":
+		return Result{Time: time.Date(now.Year(), time.December, 31, 0, 0, 0, 0, now.Location())}, nil
+	}
+
+	if wd, ok := parseWeekdayName(tokens[len(tokens)-1]); ok {
+		switch {
+		case len(tokens) == 1:
+			return p.weekday(today, wd, ""), nil
+		case len(tokens) == 2 && (tokens[0] == "this" || tokens[0] == "next"):
+			return p.weekday(today, wd, tokens[0]), nil
+		}
+		return Result{}, fmt.Errorf("unrecognized date")
+	}
+
+	if len(tokens) == 1 {
+		if m := isoDateRe.FindStringSubmatch(tokens[0]); m != nil {
+			year, _ := strconv.Atoi(m[1])
+			month, _ := strconv.Atoi(m[2])
+			day, _ := strconv.Atoi(m[3])
+			return p.date(today, year, time.Month(month), day, true)
+		}
+		if m := slashDateRe.FindStringSubmatch(tokens[0]); m != nil {
+			month, _ := strconv.Atoi(m[1])
+			day, _ := strconv.Atoi(m[2])
+			year, _ := strconv.Atoi(m[3])
+			if len(m[3]) == 2 {
+				year += 2000
+			}
+			return p.date(today, year, time.Month(month), day, m[3] != "")
+		}
+	}
+
+	return p.monthDay(tokens, today)
+}
+
+// weekday returns the next given weekday after today. With "this", today counts; with "next", it's the weekday in next week.
+func (p Parser) weekday(today time.Time, wd time.Weekday, qualifier string) Result {
+	days := (int(wd) - int(today.Weekday()) + 7) % 7
+	if qualifier == "this" {
+		return Result{Time: today.AddDate(0, 0, days)}
+	}
+	if days == 0 {
+		days = 7
+	}
+	coming := today.AddDate(0, 0, days)
+
+	if qualifier == "next" {
+		nextWeek := p.startOfWeek(today).AddDate(0, 0, 7)
+		res := Result{Time: nextWeek.AddDate(0, 0, (int(wd)-int(p.WeekStart)+7)%7)}
+		if !res.Time.Equal(coming) {
+			res.Ambiguity = fmt.Sprintf("using %s of next week, %s; use %q for %s", wd, res.Time.Format("Jan 2"), strings.ToLower(wd.String()[:3]), coming.Format("Jan 2"))
+		}
+		return res
+	}
+
+	res := Result{Time: coming}
+	if days == 7 {
+		res.Ambiguity = fmt.Sprintf("today is %s, so next %s (%s) was used; use \"today\" for today", wd, wd, coming.Format("Jan 2"))
+	}
+	return res
+}
+
+// monthDay parses a date with a month name, like "nov 3", "3rd november" or "nov 3 2026"
+func (p Parser) monthDay(tokens []string, today time.Time) (Result, error) {
+	if len(tokens) < 2 || len(tokens) > 3 {
+		return Result{}, fmt.Errorf("unrecognized date")
+	}
+	month, ok := parseMonthName(tokens[0])
+	dayToken := tokens[1]
+	if !ok {
+		month, ok = parseMonthName(tokens[1])
+		dayToken = tokens[0]
+	}
+	m := dayNumRe.FindStringSubmatch(dayToken)
+	if !ok || m == nil {
+		return Result{}, fmt.Errorf("unrecognized date")
+	}
+	day, _ := strconv.Atoi(m[1])
+	if len(tokens) == 2 {
+		return p.date(today, 0, month, day, false)
+	}
+	year, err := strconv.Atoi(tokens[2])
+	if err != nil || len(tokens[2]) != 4 {
+		return Result{}, fmt.Errorf("invalid year")
+	}
+	return p.date(today, year, month, day, true)
+}
+
+// date validates a calendar date. Without a year, the current year is used, and if that's already passed it's reported as ambiguous.
+func (p Parser) date(today time.Time, year int, month time.Month, day int, hasYear bool) (Result, error) {
+	if !hasYear {
+		year = today.Year()
+	}
+	t := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if month < time.January || month > time.December || t.Month() != month || t.Day() != day {
+		return Result{}, fmt.Errorf("invalid date")
+	}
+	res := Result{Time: t}
+	if !hasYear && t.Before(today) {
+		res.Ambiguity = fmt.Sprintf("%s has already passed this year; add the year for %d, e.g. \"%s %d\"",
+			t.Format("Jan 2"), year+1, strings.ToLower(t.Format("Jan 2")), year+1)
+	}
+	return res, nil
+}
+
+func (p Parser) calendar() calendar.Calendar {
+	if p.Calendar == nil {
+		return calendar.Standard()
+	}
+	return *p.Calendar
+}
+
+func (p Parser) startOfWeek(today time.Time) time.Time {
+	return today.AddDate(0, 0, -((int(today.Weekday()) - int(p.WeekStart) + 7) % 7))
+}
+
+// parseWeekdayName matches a weekday by name or a prefix of at least 3 letters, e.g. fri, thurs, wednesday
+func parseWeekdayName(s string) (time.Weekday, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), s) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseMonthName matches a month by name or a prefix of at least 3 letters, e.g. nov, sept, december
+func parseMonthName(s string) (time.Month, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+	for m := time.January; m <= time.December; m++ {
+		if strings.HasPrefix(strings.ToLower(m.String()), s) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// addDate is like time.AddDate, but clamps to the end of the month instead of overflowing, so a month after Jan 31 is Feb 28
+func addDate(t time.Time, years, months, days int) time.Time {
+	if years == 0 && months == 0 {
+		return t.AddDate(0, 0, days)
+	}
+	first := time.Date(t.Year()+years, t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	day := min(t.Day(), last)
+	return time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()).AddDate(0, 0, days)
+}
@@ -0,0 +1,179 @@
+package dates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/webbben/task/internal/calendar"
+)
+
+// now is Monday, October 19th 2026 at 10:30
+var now = time.Date(2026, time.October, 19, 10, 30, 0, 0, time.UTC)
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	p := Parser{Now: func() time.Time { return now }, WeekStart: time.Monday}
+	tests := []struct {
+		in        string
+		want      time.Time
+		hasTime   bool
+		ambiguity string
+	}{
+		{in: "today", want: day(time.October, 19)},
+		{in: "tomorrow", want: day(time.October, 20)},
+		{in: "tmrw", want: day(time.October, 20)},
+		{in: "yesterday", want: day(time.October, 18)},
+		{in: "eod", want: day(time.October, 19)},
+		{in: "eow", want: day(time.October, 25)},
+		{in: "end of week", want: day(time.October, 25)},
+		{in: "eom", want: day(time.October, 31)},
+		{in: "end of month", want: day(time.October, 31)},
+		{in: "eoy", want: day(time.December, 31)},
+
+		{in: "fri", want: day(time.October, 23)},
+		{in: "this fri", want: day(time.October, 23)},
+		{in: "next friday", want: day(time.October, 30),
+			ambiguity: `using Friday of next week, Oct 30; use "fri" for Oct 23`},
+		{in: "monday", want: day(time.October, 26),
+			ambiguity: `today is Monday, so next Monday (Oct 26) was used; use "today" for today`},
+		{in: "this mon", want: day(time.October, 19)},
+		{in: "next mon", want: day(time.October, 26)},
+
+		{in: "in 3 days", want: day(time.October, 22)},
+		{in: "3d", want: day(time.October, 22)},
+		{in: "2 weeks ago", want: day(time.October, 5)},
+		{in: "-1w", want: day(time.October, 12)},
+		{in: "next month", want: day(time.November, 19)},
+		{in: "in 2 hours", want: now.Add(2 * time.Hour), hasTime: true},
+		{in: "30 min", want: now.Add(30 * time.Minute), hasTime: true},
+
+		{in: "2026-11-03", want: day(time.November, 3)},
+		{in: "11/3", want: day(time.November, 3)},
+		{in: "11/3/27", want: time.Date(2027, time.November, 3, 0, 0, 0, 0, time.UTC)},
+		{in: "nov 3", want: day(time.November, 3)},
+		{in: "3rd november", want: day(time.November, 3)},
+		{in: "nov 3 2027", want: time.Date(2027, time.November, 3, 0, 0, 0, 0, time.UTC)},
+		{in: "jan 5", want: day(time.January, 5),
+			ambiguity: `Jan 5 has already passed this year; add the year for 2027, e.g. "jan 5 2027"`},
+
+		{in: "fri 15:00", want: day(time.October, 23).Add(15 * time.Hour), hasTime: true},
+		{in: "tomorrow at 9am", want: day(time.October, 20).Add(9 * time.Hour), hasTime: true},
+		{in: "2026-11-03T15:00", want: day(time.November, 3).Add(15 * time.Hour), hasTime: true},
+		{in: "3 pm", want: day(time.October, 19).Add(15 * time.Hour), hasTime: true},
+		{in: "9:00", want: day(time.October, 19).Add(9 * time.Hour), hasTime: true,
+			ambiguity: `09:00 has already passed today; use "tomorrow 09:00" for tomorrow`},
+	}
+	for _, tt := range tests {
+		got, err := p.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !got.Time.Equal(tt.want) || got.HasTime != tt.hasTime {
+			t.Errorf("Parse(%q) = %v (has time: %v), want %v (has time: %v)", tt.in, got.Time, got.HasTime, tt.want, tt.hasTime)
+		}
+		if got.Ambiguity != tt.ambiguity {
+			t.Errorf("Parse(%q) ambiguity = %q, want %q", tt.in, got.Ambiguity, tt.ambiguity)
+		}
+	}
+}
+
+func TestParseAcrossDSTChange(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	// clocks in New York spring forward at 2:00 on March 14th 2027, and fall back at 2:00 on November 7th 2027
+	p := Parser{Now: func() time.Time { return time.Date(2027, time.March, 13, 10, 0, 0, 0, newYork) }, WeekStart: time.Monday}
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"tomorrow 9am", time.Date(2027, time.March, 14, 9, 0, 0, 0, newYork)},
+		{"tomorrow 15:30", time.Date(2027, time.March, 14, 15, 30, 0, 0, newYork)},
+		{"2027-03-14T23:00", time.Date(2027, time.March, 14, 23, 0, 0, 0, newYork)},
+		{"nov 7 9am", time.Date(2027, time.November, 7, 9, 0, 0, 0, newYork)},
+		{"nov 7 noon", time.Date(2027, time.November, 7, 12, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		got, err := p.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !got.Time.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, got.Time, tt.want)
+		}
+	}
+}
+
+func TestParseWeekStart(t *testing.T) {
+	tests := []struct {
+		in        string
+		weekStart time.Weekday
+		want      time.Time
+	}{
+		{"eow", time.Monday, day(time.October, 25)},
+		{"eow", time.Sunday, day(time.October, 24)},
+		{"next sun", time.Monday, day(time.November, 1)},
+		{"next sun", time.Sunday, day(time.October, 25)},
+	}
+	for _, tt := range tests {
+		p := Parser{Now: func() time.Time { return now }, WeekStart: tt.weekStart}
+		got, err := p.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) with the week starting on %s returned error: %v", tt.in, tt.weekStart, err)
+			continue
+		}
+		if !got.Time.Equal(tt.want) {
+			t.Errorf("Parse(%q) with the week starting on %s = %v, want %v", tt.in, tt.weekStart, got.Time, tt.want)
+		}
+	}
+}
+
+func TestParseBusinessDays(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	withHoliday := calendar.New(weekdays, map[string]string{"2026-10-21": "Founders' Day"})
+	fourDayWeek := calendar.New(weekdays[:4], nil)
+	friday := time.Date(2026, time.October, 23, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		now  time.Time
+		cal  *calendar.Calendar
+		want time.Time
+	}{
+		{in: "3bd", now: now, want: day(time.October, 22)},
+		{in: "in 3 business days", now: now, want: day(time.October, 22)},
+		{in: "in 5 workdays", now: now, want: day(time.October, 26)},
+		{in: "1bd", now: friday, want: day(time.October, 26)},
+		{in: "2 business days ago", now: now, want: day(time.October, 15)},
+		{in: "3bd", now: now, cal: &withHoliday, want: day(time.October, 23)},
+		{in: "3bd", now: now, cal: &fourDayWeek, want: day(time.October, 22)},
+		{in: "4bd", now: now, cal: &fourDayWeek, want: day(time.October, 26)},
+		{in: "3bd 9am", now: now, want: day(time.October, 22).Add(9 * time.Hour)},
+	}
+	for _, tt := range tests {
+		p := Parser{Now: func() time.Time { return tt.now }, WeekStart: time.Monday, Calendar: tt.cal}
+		got, err := p.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) on %s returned error: %v", tt.in, tt.now.Format("Mon Jan 2"), err)
+			continue
+		}
+		if !got.Time.Equal(tt.want) {
+			t.Errorf("Parse(%q) on %s = %v, want %v", tt.in, tt.now.Format("Mon Jan 2"), got.Time, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	p := Parser{Now: func() time.Time { return now }, WeekStart: time.Monday}
+	for _, in := range []string{"", "someday", "nov 31", "2026-02-30", "13/1", "in 2 hours 15:00", "fri 25:00", "next blah"} {
+		if got, err := p.Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", in, got.Time)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Notification is a reminder about a task
+type Notification struct {
+	TaskID string `json:"task_id"`
+	// Title is the headline, e.g. "Overdue: write report"
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Urgent is set for overdue tasks
+	Urgent bool `json:"urgent"`
+}
+
+// Notifier sends notifications somewhere the user will see them
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// New creates the notifier with the given name: log, desktop or webhook.
+// The webhook URL is only used by the webhook notifier.
+func New(name, webhookURL string, out io.Writer) (Notifier, error) {
+	switch name {
+	case "log":
+		return Log{Out: out}, nil
+	case "desktop":
+		// fail now rather than on the first reminder
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil, errors.New("the desktop notifier needs notify-send, which wasn't found")
+		}
+		return Desktop{}, nil
+	case "webhook":
+		if webhookURL == "" {
+			return nil, errors.New("the webhook notifier needs the reminders.webhook_url config value")
+		}
+		return Webhook{URL: webhookURL, Client: &http.Client{Timeout: 10 * time.Second}}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier %q", name)
+}
+
+// Log writes each notification as a line of text, e.g. to the terminal
+type Log struct {
+	Out io.Writer
+}
+
+func (l Log) Notify(n Notification) error {
+	_, err := fmt.Fprintf(l.Out, "%s  %s  %s\n", time.Now().Format("15:04"), n.Title, n.Body)
+	return err
+}
+
+// Desktop shows notifications on the desktop with notify-send
+type Desktop struct{}
+
+func (Desktop) Notify(n Notification) error {
+	args := []string{"--app-name=task"}
+	if n.Urgent {
+		args = append(args, "--urgency=critical")
+	}
+	args = append(args, n.Title, n.Body)
+	out, err := exec.Command("notify-send", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("notify-send: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Webhook posts notifications to a URL as JSON
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w Webhook) Notify(n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s returned %s", w.URL, resp.Status)
+	}
+	return nil
+}
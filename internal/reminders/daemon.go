@@ -0,0 +1,124 @@
+package reminders
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/webbben/task/internal/notify"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+)
+
+const (
+	// how often to check if the database was changed by another process
+	pollInterval = 2 * time.Second
+	// how long to wait for the database lock, so the daemon doesn't block other task commands
+	lockTimeout = 2 * time.Second
+)
+
+// Daemon watches a workspace and sends reminders for tasks that are due soon or overdue
+type Daemon struct {
+	Workspace string
+	// Notifiers are the notifiers to send reminders with, by name. Each one is only sent a reminder once.
+	Notifiers map[string]notify.Notifier
+	// Before is how long before a task due at a time of day it's reminded about
+	Before time.Duration
+	// Interval is how often tasks are checked when the database hasn't changed, so reminders are sent as time passes
+	Interval time.Duration
+	State    *State
+	Log      *log.Logger
+}
+
+// Run checks the tasks whenever the database file changes, and every Interval, until ctx is done
+func (d *Daemon) Run(ctx context.Context) error {
+	var lastMod, lastCheck time.Time
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		mod := modTime(storage.WorkspacePath(d.Workspace))
+		if !mod.Equal(lastMod) || time.Since(lastCheck) >= d.Interval {
+			lastCheck = time.Now()
+			if err := d.Check(lastCheck); err != nil {
+				d.Log.Println("error checking tasks:", err)
+			} else {
+				lastMod = mod
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check loads the tasks and sends the reminders that weren't sent yet
+func (d *Daemon) Check(now time.Time) error {
+	var all []types.Task
+	// the database is only opened while loading, so the daemon doesn't keep other task commands waiting
+	err := storage.WithWorkspace(d.Workspace, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}, func() error {
+		var err error
+		all, err = tasks.GetAllTasks()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(d.Notifiers))
+	for name := range d.Notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	due := Find(all, now, d.Before)
+	current := make(map[string]bool, len(due))
+	changed := false
+	for _, r := range due {
+		key := r.key()
+		current[key] = true
+		// state saved before reminders were tracked per notifier counts as sent by all of them
+		if _, sent := d.State.Sent[key]; sent {
+			continue
+		}
+		for _, name := range names {
+			// the state is kept per notifier, so if one of them fails, only that one tries again
+			sentKey := name + "/" + key
+			if _, sent := d.State.Sent[sentKey]; sent {
+				continue
+			}
+			if err := d.Notifiers[name].Notify(r.Notification()); err != nil {
+				// it's tried again on the next check
+				d.Log.Printf("error sending reminder for %s with %s: %v", r.Task.ID, name, err)
+				continue
+			}
+			d.State.Sent[sentKey] = now
+			changed = true
+		}
+	}
+	// forget reminders for tasks that were completed or rescheduled, so the state doesn't grow forever
+	for key := range d.State.Sent {
+		_, reminderKey, _ := strings.Cut(key, "/")
+		if !current[key] && !current[reminderKey] {
+			delete(d.State.Sent, key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return d.State.Save()
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
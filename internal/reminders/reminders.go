@@ -0,0 +1,125 @@
+package reminders
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/notify"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/types"
+)
+
+// kinds of reminders
+const (
+	Upcoming = "upcoming"
+	Overdue  = "overdue"
+)
+
+// Reminder is a task that's due soon or overdue
+type Reminder struct {
+	Task types.Task
+	Kind string
+	// Due is when the task is due: its due time, or the start of its due day
+	Due time.Time
+}
+
+// Find returns the reminders for the given tasks at now. Tasks due at a time of day are upcoming from
+// before their due time, and tasks due on a date are upcoming on that day. Both are overdue once their due time
+// or day has passed. Completed and snoozed tasks are skipped.
+func Find(all []types.Task, now time.Time, before time.Duration) []Reminder {
+	out := make([]Reminder, 0)
+	for _, t := range all {
+		if t.Status == constants.TaskStatus.Complete || t.Waiting(now) {
+			continue
+		}
+		due := t.Due()
+		upcomingFrom, overdueFrom := due.Add(-before), due
+		if !t.DueHasTime {
+			upcomingFrom, overdueFrom = due, due.AddDate(0, 0, 1)
+		}
+		switch {
+		case !now.Before(overdueFrom):
+			out = append(out, Reminder{Task: t, Kind: Overdue, Due: due})
+		case !now.Before(upcomingFrom):
+			out = append(out, Reminder{Task: t, Kind: Upcoming, Due: due})
+		}
+	}
+	return out
+}
+
+// key identifies the reminder, so it's only sent once. Moving the due date gives a new key, so the task is reminded about again.
+func (r Reminder) key() string {
+	return fmt.Sprintf("%s/%s/%d", r.Task.ID, r.Kind, r.Due.Unix())
+}
+
+// Notification describes the reminder for a notifier
+func (r Reminder) Notification() notify.Notification {
+	n := notify.Notification{TaskID: r.Task.ID, Urgent: r.Kind == Overdue}
+	when := "today"
+	if r.Task.DueHasTime {
+		when = "at " + r.Due.Format("15:04")
+	}
+	switch r.Kind {
+	case Overdue:
+		n.Title = "Overdue: " + r.Task.Title
+		n.Body = "was due " + r.Due.Format("Mon Jan 2")
+		if r.Task.DueHasTime {
+			n.Body += " " + when
+		}
+	default:
+		n.Title = "Due soon: " + r.Task.Title
+		n.Body = "due " + when
+	}
+	return n
+}
+
+// State is the set of reminders that were already sent, saved in a file so they aren't sent again after a restart
+type State struct {
+	path string
+	// Sent maps "<notifier>/<reminder key>" to when the reminder was sent with that notifier
+	Sent map[string]time.Time `json:"sent"`
+}
+
+// StatePath returns the path of the reminder state file of a workspace
+func StatePath(workspace string) string {
+	return filepath.Join(storage.AppDataPathUnix(), "reminders", workspace+".json")
+}
+
+// LoadState reads the state file at path. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{path: path, Sent: make(map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid reminder state %s: %w", path, err)
+	}
+	if s.Sent == nil {
+		s.Sent = make(map[string]time.Time)
+	}
+	return s, nil
+}
+
+// Save writes the state file, replacing it in one step so a crash can't leave it half written
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,331 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/webbben/task/internal/dates"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+)
+
+// NotifyTasksChanged is the method name of the notification sent to the client whenever a task is changed
+const NotifyTasksChanged = "tasksChanged"
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is the reply to a successful request. JSON-RPC requires the result member even when it's null.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result"`
+}
+
+// errorResponse is the reply to a failed request, which must not have a result member
+type errorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   *rpcError       `json:"error"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// TaskChange describes a change to a task, and is sent as the params of a tasksChanged notification
+type TaskChange struct {
+	Action string `json:"action"`
+	ID     string `json:"id"`
+}
+
+type handlerFunc func(s *Server, params json.RawMessage) (any, error)
+
+// errInvalidParams is returned by handlers when the given params don't match what the method expects
+var errInvalidParams = errors.New("invalid params")
+
+var handlers = map[string]handlerFunc{
+	"addTask":             addTask,
+	"getTask":             getTask,
+	"getAllTasks":         getAllTasks,
+	"addNote":             addNote,
+	"completeTask":        completeTask,
+	"findTasksByIDPrefix": findTasksByIDPrefix,
+}
+
+// readOnlyMethods don't write to the database, so it's opened read-only while they're handled
+var readOnlyMethods = map[string]bool{
+	"getTask":             true,
+	"getAllTasks":         true,
+	"findTasksByIDPrefix": true,
+}
+
+// Server reads line-delimited JSON-RPC 2.0 requests and writes responses and notifications back out, one per line.
+// The workspace's database is only opened while a request is handled, so other task processes can use it in between.
+type Server struct {
+	in          io.Reader
+	out         *json.Encoder
+	workspace   string
+	lockTimeout time.Duration
+	mu          sync.Mutex
+	changes     []TaskChange // change notifications to send once the current request has been answered
+}
+
+// NewServer creates a server for the tasks of the given workspace. lockTimeout is how long each request waits
+// for the database if another process has it locked.
+func NewServer(in io.Reader, out io.Writer, workspace string, lockTimeout time.Duration) *Server {
+	return &Server{
+		in:          in,
+		out:         json.NewEncoder(out),
+		workspace:   workspace,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Serve handles requests until the input is closed
+func (s *Server) Serve() error {
+	scanner := bufio.NewScanner(s.in)
+	// allow for large requests, e.g. long notes
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := s.handleLine(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handleLine(line []byte) error {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return s.write(errorResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error"}})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return s.reply(req.ID, nil, &rpcError{codeInvalidRequest, "invalid request"})
+	}
+
+	handler, ok := handlers[req.Method]
+	if !ok {
+		return s.reply(req.ID, nil, &rpcError{codeMethodNotFound, "method not found: " + req.Method})
+	}
+	var result any
+	opts := storage.OpenOptions{ReadOnly: readOnlyMethods[req.Method], Timeout: s.lockTimeout}
+	err := storage.WithWorkspace(s.workspace, opts, func() error {
+		var err error
+		result, err = handler(s, req.Params)
+		return err
+	})
+	if err != nil {
+		code := codeServerError
+		if errors.Is(err, errInvalidParams) {
+			code = codeInvalidParams
+		}
+		err = s.reply(req.ID, nil, &rpcError{code, err.Error()})
+	} else {
+		err = s.reply(req.ID, result, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return s.flushChanges()
+}
+
+// reply sends a response for the given request ID. requests without an ID are notifications, so they don't get a response.
+func (s *Server) reply(id json.RawMessage, result any, rpcErr *rpcError) error {
+	if id == nil {
+		return nil
+	}
+	if rpcErr != nil {
+		return s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: rpcErr})
+	}
+	return s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// Notify sends a notification to the client
+func (s *Server) Notify(method string, params any) error {
+	return s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) write(v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.Encode(v)
+}
+
+// notifyChange queues a change notification, which is sent after the response to the current request
+func (s *Server) notifyChange(action, id string) {
+	s.changes = append(s.changes, TaskChange{Action: action, ID: id})
+}
+
+func (s *Server) flushChanges() error {
+	changes := s.changes
+	s.changes = nil
+	for _, c := range changes {
+		if err := s.Notify(NotifyTasksChanged, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return errors.Join(errInvalidParams, errors.New("params required"))
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return errors.Join(errInvalidParams, err)
+	}
+	return nil
+}
+
+type addTaskParams struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Tags        []string  `json:"tags"`
+	DueDate     time.Time `json:"due_date"`
+	// DueHasTime means the task is due at the exact time of DueDate, rather than on its date
+	DueHasTime bool `json:"due_has_time"`
+}
+
+func addTask(s *Server, params json.RawMessage) (any, error) {
+	var p addTaskParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Title == "" {
+		return nil, errors.Join(errInvalidParams, errors.New("title is required"))
+	}
+	// same default as the CLI
+	if p.DueDate.IsZero() {
+		due, err := dates.ParseDue("")
+		if err != nil {
+			return nil, err
+		}
+		p.DueDate, p.DueHasTime = due.Time, due.HasTime
+	}
+	task := types.Task{
+		Title:       p.Title,
+		Description: p.Description,
+		Category:    p.Category,
+		Tags:        p.Tags,
+	}
+	task.SetDue(p.DueDate, p.DueHasTime)
+	t, err := tasks.CreateTask(task)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyChange("add", t.ID)
+	return t, nil
+}
+
+type idParams struct {
+	ID string `json:"id"`
+}
+
+func getTask(s *Server, params json.RawMessage) (any, error) {
+	var p idParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	return tasks.GetTask(p.ID)
+}
+
+func getAllTasks(s *Server, params json.RawMessage) (any, error) {
+	t, err := tasks.GetAllTasks()
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		// send an empty list instead of null
+		return []any{}, nil
+	}
+	return t, nil
+}
+
+type addNoteParams struct {
+	ID    string `json:"id"`
+	Note  string `json:"note"`
+	Title string `json:"title"`
+	// Name is what title used to be called, still accepted so older clients keep working
+	Name string `json:"name"`
+}
+
+func addNote(s *Server, params json.RawMessage) (any, error) {
+	var p addNoteParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Note == "" {
+		return nil, errors.Join(errInvalidParams, errors.New("note is required"))
+	}
+	if p.Title == "" {
+		p.Title = p.Name
+	}
+	note, err := tasks.AddNote(p.ID, p.Note, p.Title)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyChange("note", p.ID)
+	return note, nil
+}
+
+func completeTask(s *Server, params json.RawMessage) (any, error) {
+	var p idParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if err := tasks.CompleteTask(p.ID); err != nil {
+		return nil, err
+	}
+	s.notifyChange("complete", p.ID)
+	return true, nil
+}
+
+type prefixParams struct {
+	Prefix string `json:"prefix"`
+}
+
+func findTasksByIDPrefix(s *Server, params json.RawMessage) (any, error) {
+	var p prefixParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	ids, err := tasks.FindTasksByIDPrefix(p.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		return []string{}, nil
+	}
+	return ids, nil
+}
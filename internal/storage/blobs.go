@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	blobDir = "attachments"
+
+	// blobGracePeriod protects newly stored blobs from garbage collection, since a blob is stored before the task that uses it is saved
+	blobGracePeriod = time.Hour
+)
+
+// BlobPath returns the path of the blob with the given hash in the attachment store.
+// blobs are spread over sub directories by the first two characters of their hash, like git objects.
+func BlobPath(hash string) string {
+	return filepath.Join(AppDataPathUnix(), blobDir, hash[:2], hash[2:])
+}
+
+// StoreBlob copies the given file into the attachment store, named by the sha256 hash of its content.
+// the store is shared by all workspaces, so identical files are only stored once.
+func StoreBlob(path string) (hash string, size int64, err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer src.Close()
+
+	// write to a temp file while hashing, since the name isn't known until the whole file is read
+	dir := filepath.Join(AppDataPathUnix(), blobDir)
+	if err := ensureDir(dir); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(dir, "tmp-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err = io.Copy(io.MultiWriter(tmp, h), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to copy %s: %w", path, err)
+	}
+
+	hash = hex.EncodeToString(h.Sum(nil))
+	dest := BlobPath(hash)
+	if _, err := os.Stat(dest); err == nil {
+		// already stored; touch it so it isn't collected before the task is saved
+		now := time.Now()
+		return hash, size, os.Chtimes(dest, now, now)
+	}
+	if err := ensureDir(filepath.Dir(dest)); err != nil {
+		return "", 0, err
+	}
+	if err := os.Chmod(tmp.Name(), 0400); err != nil {
+		return "", 0, err
+	}
+	return hash, size, os.Rename(tmp.Name(), dest)
+}
+
+// RemoveUnusedBlobs deletes every blob that isn't in the given set of hashes, except for ones stored very recently.
+// it returns the number of blobs removed and the bytes freed.
+func RemoveUnusedBlobs(used map[string]bool) (removed int, freed int64, err error) {
+	root := filepath.Join(AppDataPathUnix(), blobDir)
+	cutoff := time.Now().Add(-blobGracePeriod)
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if used[filepath.Dir(rel)+filepath.Base(rel)] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// leftover temp files are cleaned up too, once they're old enough that no attach could still be writing them
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		freed += info.Size()
+		return nil
+	})
+	return removed, freed, err
+}
@@ -1,20 +1,29 @@
 package storage
 
 import (
+	"errors"
 	"log"
 	"os"
 	"os/user"
 	"path/filepath"
+	"time"
 
 	"go.etcd.io/bbolt"
 )
 
-var db *bbolt.DB
+var (
+	db       *bbolt.DB
+	lockPath string // path of the lock info file written for the currently open database, if it was opened for writing
+)
 
 const (
 	TASK_DB        = "tasks.db"
 	ACTIVE_BUCKET  = "active"
 	ARCHIVE_BUCKET = "archive"
+	// META_BUCKET holds state that isn't a task, e.g. the running timer
+	META_BUCKET = "meta"
+	// INDEX_BUCKET holds the full-text search index. It's created the first time tasks are written after an upgrade.
+	INDEX_BUCKET = "index"
 )
 
 func ConfigPathUnix() string {
@@ -53,33 +62,119 @@ func ensureDir(path string) error {
 	return nil
 }
 
-// OpenDatabase opens the BoltDB database of the given name
+// OpenOptions controls how the database is opened
+type OpenOptions struct {
+	// ReadOnly opens the database with a shared lock, so multiple readers can have it open at the same time.
+	// Any attempt to write to the database will fail.
+	ReadOnly bool
+	// Timeout is how long to keep retrying if another process has the database locked.
+	// zero means only try once.
+	Timeout time.Duration
+}
+
+const (
+	// how long a single attempt at getting the file lock waits before backing off
+	attemptTimeout = 100 * time.Millisecond
+	minBackoff     = 50 * time.Millisecond
+	maxBackoff     = time.Second
+)
+
+// DefaultOpenOptions are used by OpenDatabase
+var DefaultOpenOptions = OpenOptions{
+	Timeout: 5 * time.Second,
+}
+
+// OpenDatabase opens the BoltDB database of the given name for reading and writing
 func OpenDatabase(name string) error {
-	fullpath := filepath.Join(AppDataPathUnix(), name)
+	return OpenDatabaseWithOptions(name, DefaultOpenOptions)
+}
+
+// OpenDatabaseWithOptions opens the BoltDB database of the given name.
+//
+// If another process has the database locked, opening is retried with a backoff until the timeout is reached,
+// at which point an error describing which process holds the lock is returned.
+func OpenDatabaseWithOptions(name string, opts OpenOptions) error {
+	return openPath(filepath.Join(AppDataPathUnix(), name), opts)
+}
+
+// OpenDatabaseFile opens the BoltDB database at the given path instead of one in the app data directory,
+// e.g. a temporary database in tests
+func OpenDatabaseFile(path string, opts OpenOptions) error {
+	return openPath(path, opts)
+}
+
+func openPath(fullpath string, opts OpenOptions) error {
+	// a read-only open can't create the database file, so the first ever open needs to be writable
+	if opts.ReadOnly {
+		if _, err := os.Stat(fullpath); os.IsNotExist(err) {
+			opts.ReadOnly = false
+		}
+	}
+
 	var err error
-	db, err = bbolt.Open(fullpath, 0600, nil)
+	db, err = openWithRetry(fullpath, opts)
 	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return lockedError(fullpath)
+		}
 		return err
 	}
+	if opts.ReadOnly {
+		lockPath = ""
+		return nil
+	}
+	lockPath = writeLockInfo(fullpath)
+
+	return ensureBuckets(db)
+}
 
-	// Ensure the tasks bucket exists
-	return db.Update(func(tx *bbolt.Tx) error {
+// ensureBuckets makes sure the tasks buckets exist
+func ensureBuckets(d *bbolt.DB) error {
+	return d.Update(func(tx *bbolt.Tx) error {
 		_, err := tx.CreateBucketIfNotExists([]byte(ACTIVE_BUCKET))
 		if err != nil {
 			return err
 		}
 		_, err = tx.CreateBucketIfNotExists([]byte(ARCHIVE_BUCKET))
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte(META_BUCKET))
 		return err
 	})
 }
 
+func openWithRetry(path string, opts OpenOptions) (*bbolt.DB, error) {
+	deadline := time.Now().Add(opts.Timeout)
+	backoff := minBackoff
+	for {
+		d, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: attemptTimeout, ReadOnly: opts.ReadOnly})
+		if err == nil || !errors.Is(err, bbolt.ErrTimeout) {
+			return d, err
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, err
+		}
+		time.Sleep(min(backoff, remaining))
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
 // CloseDatabase closes the BoltDB database
 func CloseDatabase() {
 	if db != nil {
 		db.Close()
+		removeLockInfo(lockPath)
+		db = nil
 	}
 }
 
+// ReadOnly returns true if the database was opened in read-only mode
+func ReadOnly() bool {
+	return db != nil && db.IsReadOnly()
+}
+
 // DB returns the BoltDB database
 func DB() *bbolt.DB {
 	return db
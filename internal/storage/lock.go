@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// LockedError is returned when the database couldn't be opened because another process is holding the file lock
+type LockedError struct {
+	PID     int    // 0 if the process holding the lock is unknown
+	Command string // the command line of the process holding the lock, if known
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return "database is locked by another task process"
+	}
+	if e.Command == "" {
+		return fmt.Sprintf("database is locked by PID %d", e.PID)
+	}
+	return fmt.Sprintf("database is locked by PID %d (%s)", e.PID, e.Command)
+}
+
+// bbolt uses flock, which doesn't tell us who is holding the lock. so next to the database file, we keep a small file
+// recording the PID and command of the process that has it open, so that we can give a useful error message.
+func lockInfoPath(dbPath string) string {
+	return dbPath + ".lock"
+}
+
+// writeLockInfo records this process as the holder of the database lock, and returns the path of the lock info file.
+// only writers record themselves: readers share the lock, so they're never what another process is waiting on alone,
+// and overwriting the writer's info would hide who is really holding the lock.
+//
+// this is best effort; if it fails, the only consequence is a less helpful error message for other processes.
+func writeLockInfo(dbPath string) string {
+	path := lockInfoPath(dbPath)
+	args := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	content := fmt.Sprintf("%d\nrw\n%s\n", os.Getpid(), strings.Join(args, " "))
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return ""
+	}
+	return path
+}
+
+// removeLockInfo removes the lock info file, but only if it still belongs to this process
+func removeLockInfo(path string) {
+	if path == "" {
+		return
+	}
+	pid, _ := readLockInfo(path)
+	if pid == os.Getpid() {
+		os.Remove(path)
+	}
+}
+
+// removeStaleLockInfo removes the lock info file of a database that nothing has open, e.g. one left behind by a process that crashed
+func removeStaleLockInfo(dbPath string) {
+	os.Remove(lockInfoPath(dbPath))
+}
+
+func readLockInfo(path string) (pid int, command string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, ""
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	pid, err = strconv.Atoi(lines[0])
+	if err != nil {
+		return 0, ""
+	}
+	if len(lines) >= 3 {
+		command = lines[2]
+	}
+	return pid, command
+}
+
+func lockedError(dbPath string) error {
+	pid, command := readLockInfo(lockInfoPath(dbPath))
+	if pid == os.Getpid() || !processExists(pid) {
+		// stale info doesn't help anyone
+		pid, command = 0, ""
+	}
+	return &LockedError{PID: pid, Command: command}
+}
+
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// signal 0 doesn't actually send anything, it just checks if the process exists
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// DefaultWorkspace is the workspace used when no other workspace is selected. It uses the original TASK_DB file.
+	DefaultWorkspace = "default"
+
+	workspaceDir = "workspaces"
+	workspaceExt = ".db"
+)
+
+var (
+	workspaceNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	currentWorkspace = DefaultWorkspace
+)
+
+// WorkspacePath returns the path of the database file for the given workspace
+func WorkspacePath(name string) string {
+	if name == DefaultWorkspace {
+		return filepath.Join(AppDataPathUnix(), TASK_DB)
+	}
+	return filepath.Join(AppDataPathUnix(), workspaceDir, name+workspaceExt)
+}
+
+// ValidateWorkspaceName returns an error if the given name can't be used for a workspace
+func ValidateWorkspaceName(name string) error {
+	if !workspaceNameRe.MatchString(name) {
+		return fmt.Errorf("invalid workspace name %q: only letters, numbers, '-' and '_' are allowed", name)
+	}
+	return nil
+}
+
+// WorkspaceExists returns true if the given workspace has a database file. The default workspace always exists,
+// and a workspace with an invalid name never does.
+func WorkspaceExists(name string) bool {
+	if name == DefaultWorkspace {
+		return true
+	}
+	// an invalid name could point outside the workspace directory, e.g. "../foo"
+	if ValidateWorkspaceName(name) != nil {
+		return false
+	}
+	_, err := os.Stat(WorkspacePath(name))
+	return err == nil
+}
+
+// ListWorkspaces returns the names of all workspaces, starting with the default workspace
+func ListWorkspaces() ([]string, error) {
+	names := []string{DefaultWorkspace}
+	entries, err := os.ReadDir(filepath.Join(AppDataPathUnix(), workspaceDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return names, nil
+		}
+		return names, err
+	}
+	others := make([]string, 0)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), workspaceExt) {
+			continue
+		}
+		others = append(others, strings.TrimSuffix(e.Name(), workspaceExt))
+	}
+	sort.Strings(others)
+	return append(names, others...), nil
+}
+
+// OpenWorkspace opens the database of the given workspace, which then becomes the current workspace
+func OpenWorkspace(name string, opts OpenOptions) error {
+	if err := ValidateWorkspaceName(name); err != nil {
+		return err
+	}
+	if !WorkspaceExists(name) {
+		return fmt.Errorf("workspace %q does not exist (create it with: task workspace create %s)", name, name)
+	}
+	if err := openPath(WorkspacePath(name), opts); err != nil {
+		return err
+	}
+	currentWorkspace = name
+	return nil
+}
+
+// WithWorkspace opens the given workspace, runs fn, and closes the database again.
+// This is for long running processes like the TUI, so they only hold the database lock while actually using it.
+func WithWorkspace(name string, opts OpenOptions, fn func() error) error {
+	if err := OpenWorkspace(name, opts); err != nil {
+		return err
+	}
+	defer CloseDatabase()
+	return fn()
+}
+
+// CurrentWorkspace returns the name of the workspace whose database is open
+func CurrentWorkspace() string {
+	return currentWorkspace
+}
+
+// CreateWorkspace creates the database file for a new workspace
+func CreateWorkspace(name string) error {
+	if err := ValidateWorkspaceName(name); err != nil {
+		return err
+	}
+	if WorkspaceExists(name) {
+		return fmt.Errorf("workspace %q already exists", name)
+	}
+	path := WorkspacePath(name)
+	if err := ensureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	d, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return ensureBuckets(d)
+}
+
+// RenameWorkspace renames a workspace's database file. The default workspace can't be renamed.
+func RenameWorkspace(oldName, newName string) error {
+	if err := ValidateWorkspaceName(oldName); err != nil {
+		return err
+	}
+	if err := ValidateWorkspaceName(newName); err != nil {
+		return err
+	}
+	if oldName == DefaultWorkspace {
+		return errors.New("the default workspace can't be renamed")
+	}
+	if !WorkspaceExists(oldName) {
+		return fmt.Errorf("workspace %q does not exist", oldName)
+	}
+	if WorkspaceExists(newName) {
+		return fmt.Errorf("workspace %q already exists", newName)
+	}
+	if err := ensureNotInUse(oldName); err != nil {
+		return err
+	}
+	if err := os.Rename(WorkspacePath(oldName), WorkspacePath(newName)); err != nil {
+		return err
+	}
+	// nothing has the database open, so any lock info left next to it is stale
+	removeStaleLockInfo(WorkspacePath(oldName))
+	return nil
+}
+
+// DeleteWorkspace deletes a workspace's database file, along with all of its tasks. The default workspace can't be deleted.
+func DeleteWorkspace(name string) error {
+	if err := ValidateWorkspaceName(name); err != nil {
+		return err
+	}
+	if name == DefaultWorkspace {
+		return errors.New("the default workspace can't be deleted")
+	}
+	if !WorkspaceExists(name) {
+		return fmt.Errorf("workspace %q does not exist", name)
+	}
+	if err := ensureNotInUse(name); err != nil {
+		return err
+	}
+	if err := os.Remove(WorkspacePath(name)); err != nil {
+		return err
+	}
+	removeStaleLockInfo(WorkspacePath(name))
+	return nil
+}
+
+// ensureNotInUse returns an error if another process has the given workspace's database open
+func ensureNotInUse(name string) error {
+	path := WorkspacePath(name)
+	d, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: attemptTimeout})
+	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return lockedError(path)
+		}
+		return err
+	}
+	return d.Close()
+}
@@ -0,0 +1,24 @@
+package storage
+
+import "testing"
+
+func TestWorkspaceTraversalNamesAreRefused(t *testing.T) {
+	for _, name := range []string{"../../foo", "..", "a/b", "/tmp/foo", "", "foo.db"} {
+		if err := ValidateWorkspaceName(name); err == nil {
+			t.Errorf("ValidateWorkspaceName(%q) accepted the name", name)
+		}
+		if WorkspaceExists(name) {
+			t.Errorf("WorkspaceExists(%q) = true", name)
+		}
+		// these fail on the name, before anything on disk is looked at
+		if err := DeleteWorkspace(name); err == nil {
+			t.Errorf("DeleteWorkspace(%q) succeeded", name)
+		}
+		if err := RenameWorkspace(name, "renamed"); err == nil {
+			t.Errorf("RenameWorkspace(%q, \"renamed\") succeeded", name)
+		}
+		if err := RenameWorkspace("default", name); err == nil {
+			t.Errorf("RenameWorkspace(\"default\", %q) succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tasks
+
+import (
+	"sort"
+	"time"
+
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+// why a task is on a day of the agenda
+const (
+	AgendaWakes = "wakes"
+	AgendaDue   = "due"
+	AgendaDone  = "done"
+)
+
+// AgendaEntry is a task shown on a day of the agenda
+type AgendaEntry struct {
+	Task types.Task
+	// Kind is why the task is on that day: AgendaWakes, AgendaDue or AgendaDone
+	Kind string
+}
+
+// AgendaDay is a day of the agenda and its tasks
+type AgendaDay struct {
+	Date    time.Time
+	Entries []AgendaEntry
+}
+
+// Agenda groups tasks by day, for the given number of days starting today.
+//
+// Unfinished tasks are shown on the day they're due, or in overdue if that's before today. Snoozed tasks are shown on the day
+// they wake up instead, and again on their due date if that's later. Completed tasks are shown on the day they were completed.
+// Tasks have no recurrence, so nothing is projected past a task's own dates.
+func Agenda(all []types.Task, now time.Time, days int) (overdue []types.Task, agenda []AgendaDay) {
+	today := util.RoundDateDown(now)
+	agenda = make([]AgendaDay, days)
+	for i := range agenda {
+		agenda[i].Date = today.AddDate(0, 0, i)
+	}
+	// add puts the task on the given day, if it's in the agenda
+	add := func(day time.Time, t types.Task, kind string) {
+		for i := range agenda {
+			if agenda[i].Date.Equal(util.RoundDateDown(day)) {
+				agenda[i].Entries = append(agenda[i].Entries, AgendaEntry{Task: t, Kind: kind})
+				return
+			}
+		}
+	}
+
+	for _, t := range all {
+		switch {
+		case t.Status == constants.TaskStatus.Complete:
+			add(t.LastUpdate.Local(), t, AgendaDone)
+		case t.Waiting(now):
+			wake := util.RoundDateDown(t.WaitUntil.Local())
+			add(wake, t, AgendaWakes)
+			if t.Due().After(wake) {
+				add(t.Due(), t, AgendaDue)
+			}
+		case t.Due().Before(today):
+			overdue = append(overdue, t)
+		default:
+			add(t.Due(), t, AgendaDue)
+		}
+	}
+
+	sort.SliceStable(overdue, func(i, j int) bool { return overdue[i].Due().Before(overdue[j].Due()) })
+	kindOrder := map[string]int{AgendaWakes: 0, AgendaDue: 1, AgendaDone: 2}
+	for _, day := range agenda {
+		entries := day.Entries
+		sort.SliceStable(entries, func(i, j int) bool {
+			a, b := entries[i], entries[j]
+			if a.Kind != b.Kind {
+				return kindOrder[a.Kind] < kindOrder[b.Kind]
+			}
+			if !a.Task.Due().Equal(b.Task.Due()) {
+				return a.Task.Due().Before(b.Task.Due())
+			}
+			return a.Task.Priority > b.Task.Priority
+		})
+	}
+	return overdue, agenda
+}
@@ -33,6 +33,10 @@ func CompleteTask(id string) error {
 		if err != nil {
 			return fmt.Errorf("failed to delete task from active bucket: %s", err.Error())
 		}
+		// a running timer stops when the task is completed
+		if err := clearTimerTx(tx, id); err != nil {
+			return err
+		}
 		// set the status to complete
 		taskData, err = setTaskDataComplete(taskData)
 		if err != nil {
@@ -46,7 +50,18 @@ func CompleteTask(id string) error {
 		}
 		// generate complete random task ID to free up title-based ones for active tasks
 		archiveID := GenerateTaskID("")
-		return monthBucket.Put([]byte(archiveID), taskData)
+		if err := monthBucket.Put([]byte(archiveID), taskData); err != nil {
+			return err
+		}
+		// the task stays searchable after it's archived
+		if err := unindexTaskTx(tx, activeDocKey(id)); err != nil {
+			return err
+		}
+		task, err := unpackTaskJson(taskData)
+		if err != nil {
+			return err
+		}
+		return indexTaskTx(tx, archiveDocKey(bucketName, archiveID), task)
 	})
 }
 
@@ -70,6 +85,9 @@ func setTaskDataComplete(taskData []byte) ([]byte, error) {
 	}
 	task.Status = constants.TaskStatus.Complete
 	task.LastUpdate = time.Now()
+	if task.TimerRunning() {
+		task.TimeLog[len(task.TimeLog)-1].End = task.LastUpdate
+	}
 	return json.Marshal(task)
 }
 
@@ -83,8 +101,10 @@ func GetCompletedTasks(lookbackDate time.Time) ([]types.Task, error) {
 	// ex: if today is Nov 10th and lookback date is Sept 5th, the month buckets
 	// will be: Nov, Oct, Sept
 	buckets := make([]string, 0)
-	curMonth := time.Now()
-	for curMonth.Year() >= lookbackDate.Year() && curMonth.Month() >= lookbackDate.Month() {
+	// start from the first of the month, so AddDate doesn't skip short months (e.g. Mar 31 - 1 month = Mar 3)
+	curMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	// bucket names sort by date, which also works across years
+	for monthBucketName(curMonth) >= monthBucketName(lookbackDate) {
 		buckets = append(buckets, monthBucketName(curMonth))
 		curMonth = curMonth.AddDate(0, -1, 0)
 	}
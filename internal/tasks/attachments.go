@@ -0,0 +1,80 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// AddAttachment adds a link or stored file to a task
+func AddAttachment(taskID string, a types.Attachment) (types.Attachment, error) {
+	a.Added = time.Now()
+	return a, updateTask(taskID, func(t *types.Task) error {
+		t.Attachments = append(t.Attachments, a)
+		return nil
+	})
+}
+
+// RemoveAttachment removes the nth attachment (starting at 1) from a task.
+// stored files are left in the attachment store until it's garbage collected, since other tasks may use the same file.
+func RemoveAttachment(taskID string, n int) (types.Attachment, error) {
+	var a types.Attachment
+	err := updateTask(taskID, func(t *types.Task) error {
+		if n < 1 || n > len(t.Attachments) {
+			return fmt.Errorf("task has no attachment %d", n)
+		}
+		a = t.Attachments[n-1]
+		t.Attachments = append(t.Attachments[:n-1], t.Attachments[n:]...)
+		return nil
+	})
+	return a, err
+}
+
+// UsedBlobs adds the hashes of all stored files attached to active or archived tasks to the given set
+func UsedBlobs(used map[string]bool) error {
+	db := storage.DB()
+	if db == nil {
+		return errors.New("failed to get task database")
+	}
+
+	collect := func(k, v []byte) error {
+		// archive month buckets show up as nested buckets with a nil value
+		if v == nil {
+			return nil
+		}
+		var t types.Task
+		if err := json.Unmarshal(v, &t); err != nil {
+			return err
+		}
+		for _, a := range t.Attachments {
+			if a.Hash != "" {
+				used[a.Hash] = true
+			}
+		}
+		return nil
+	}
+
+	return db.View(func(tx *bbolt.Tx) error {
+		if b := tx.Bucket([]byte(storage.ACTIVE_BUCKET)); b != nil {
+			if err := b.ForEach(collect); err != nil {
+				return err
+			}
+		}
+		archive := tx.Bucket([]byte(storage.ARCHIVE_BUCKET))
+		if archive == nil {
+			return nil
+		}
+		return archive.ForEach(func(month, v []byte) error {
+			monthBucket := archive.Bucket(month)
+			if monthBucket == nil {
+				return nil
+			}
+			return monthBucket.ForEach(collect)
+		})
+	})
+}
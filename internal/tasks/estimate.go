@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/webbben/task/internal/types"
+)
+
+// ParseEstimate parses an estimate given as a duration (e.g. 2h, 1h30m, 45m) or as story points (e.g. 3pt).
+// an empty string means no estimate, which returns nil.
+func ParseEstimate(s string) (*types.Estimate, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return nil, nil
+	}
+	for _, suffix := range []string{"pts", "pt", "p"} {
+		if num, ok := strings.CutSuffix(s, suffix); ok {
+			points, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+			if err != nil || points <= 0 {
+				return nil, fmt.Errorf("invalid estimate %q: points must be a positive number, e.g. 3pt", s)
+			}
+			return &types.Estimate{Points: points}, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid estimate %q: expected a duration (e.g. 2h, 45m) or points (e.g. 3pt)", s)
+	}
+	return &types.Estimate{Time: d}, nil
+}
@@ -0,0 +1,347 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// the search index is kept in the index bucket, and updated in the same transaction as every task write.
+// the terms bucket maps each word to the documents it's in, and the docs bucket maps each document to its words,
+// so a document's postings can be removed when it changes. a document is an active task ("active/<id>")
+// or an archived one ("archive/<month>/<archive id>").
+const (
+	termsBucket = "terms"
+	docsBucket  = "docs"
+	// words in the title count this many times more than words in the description and notes
+	titleWeight = 3
+	// words are clipped to this many bytes, since they're used as keys and bbolt rejects keys over 32KB.
+	// a long run of letters like a pasted hash still matches its first part.
+	maxWordBytes = 64
+)
+
+// ErrNoIndex is returned when searching a database the search index wasn't built for yet
+var ErrNoIndex = errors.New("the search index hasn't been built yet; run 'task reindex' to build it")
+
+// postings maps document keys to the weighted number of times a term occurs in them
+type postings map[string]int
+
+func activeDocKey(id string) string {
+	return storage.ACTIVE_BUCKET + "/" + id
+}
+
+func archiveDocKey(month, archiveID string) string {
+	return storage.ARCHIVE_BUCKET + "/" + month + "/" + archiveID
+}
+
+// tokenize splits text into lowercase words made of letters and digits
+func tokenize(s string) []string {
+	spans := wordSpans(s)
+	words := make([]string, len(spans))
+	for i, span := range spans {
+		words[i] = normalizeWord(s[span[0]:span[1]])
+	}
+	return words
+}
+
+// normalizeWord lowercases a word and clips it to maxWordBytes, without cutting a character in half
+func normalizeWord(word string) string {
+	word = strings.ToLower(word)
+	if len(word) <= maxWordBytes {
+		return word
+	}
+	end := maxWordBytes
+	for end > 0 && !utf8.RuneStart(word[end]) {
+		end--
+	}
+	return word[:end]
+}
+
+// wordSpans returns the start and end byte offsets of the words in s.
+// Chinese and Japanese aren't written with spaces between words, so each of their characters is a word of its own;
+// searching for a longer word then searches for its characters as a phrase.
+func wordSpans(s string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range s + " " {
+		if isWordRune(r) && !isIdeograph(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+		if isIdeograph(r) {
+			spans = append(spans, [2]int{i, i + utf8.RuneLen(r)})
+		}
+	}
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isIdeograph(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// searchFields returns the text of a task that's searched: its title, description, and the titles and content of its notes
+func searchFields(t types.Task) []string {
+	fields := []string{t.Title, t.Description}
+	for _, n := range t.Notes {
+		fields = append(fields, n.Title, n.Content)
+	}
+	return fields
+}
+
+// termFreqs counts the occurrences of each word in a task, with title words weighted higher
+func termFreqs(t types.Task) map[string]int {
+	freqs := make(map[string]int)
+	for i, field := range searchFields(t) {
+		weight := 1
+		if i == 0 {
+			weight = titleWeight
+		}
+		for _, term := range tokenize(field) {
+			freqs[term] += weight
+		}
+	}
+	return freqs
+}
+
+// indexTaskTx updates the index for a task that was saved under the given document key
+func indexTaskTx(tx *bbolt.Tx, key string, t types.Task) error {
+	terms, docs, fresh, err := openIndexTx(tx)
+	if err != nil || fresh {
+		return err
+	}
+	if err := removeDocTx(terms, docs, key); err != nil {
+		return err
+	}
+	return addDocTx(terms, docs, key, t)
+}
+
+// unindexTaskTx removes a task that was deleted or moved from the index
+func unindexTaskTx(tx *bbolt.Tx, key string) error {
+	terms, docs, fresh, err := openIndexTx(tx)
+	if err != nil || fresh {
+		return err
+	}
+	return removeDocTx(terms, docs, key)
+}
+
+// openIndexTx returns the index buckets, building the index first if it doesn't exist yet.
+// fresh is set when it was just built, in which case it already covers the change being made.
+func openIndexTx(tx *bbolt.Tx) (terms, docs *bbolt.Bucket, fresh bool, err error) {
+	if tx.Bucket([]byte(storage.INDEX_BUCKET)) == nil {
+		if _, err := rebuildIndexTx(tx); err != nil {
+			return nil, nil, false, err
+		}
+		fresh = true
+	}
+	idx := tx.Bucket([]byte(storage.INDEX_BUCKET))
+	terms, docs = idx.Bucket([]byte(termsBucket)), idx.Bucket([]byte(docsBucket))
+	if terms == nil || docs == nil {
+		return nil, nil, false, errors.New("the search index is damaged; run 'task reindex' to rebuild it")
+	}
+	return terms, docs, fresh, nil
+}
+
+func addDocTx(terms, docs *bbolt.Bucket, key string, t types.Task) error {
+	freqs := termFreqs(t)
+	words := make([]string, 0, len(freqs))
+	for term, n := range freqs {
+		p, err := getPostings(terms, term)
+		if err != nil {
+			return err
+		}
+		p[key] = n
+		if err := putPostings(terms, term, p); err != nil {
+			return err
+		}
+		words = append(words, term)
+	}
+	sort.Strings(words)
+	data, err := json.Marshal(words)
+	if err != nil {
+		return err
+	}
+	return docs.Put([]byte(key), data)
+}
+
+func removeDocTx(terms, docs *bbolt.Bucket, key string) error {
+	data := docs.Get([]byte(key))
+	if data == nil {
+		return nil
+	}
+	var words []string
+	if err := json.Unmarshal(data, &words); err != nil {
+		return err
+	}
+	for _, term := range words {
+		p, err := getPostings(terms, term)
+		if err != nil {
+			return err
+		}
+		delete(p, key)
+		if len(p) == 0 {
+			err = terms.Delete([]byte(term))
+		} else {
+			err = putPostings(terms, term, p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return docs.Delete([]byte(key))
+}
+
+func getPostings(terms *bbolt.Bucket, term string) (postings, error) {
+	p := make(postings)
+	data := terms.Get([]byte(term))
+	if data == nil {
+		return p, nil
+	}
+	return p, json.Unmarshal(data, &p)
+}
+
+func putPostings(terms *bbolt.Bucket, term string, p postings) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return terms.Put([]byte(term), data)
+}
+
+// RebuildIndex builds the search index again from every active and archived task, and returns how many tasks it covers
+func RebuildIndex() (int, error) {
+	db := storage.DB()
+	if db == nil {
+		return 0, errors.New("failed to get task database")
+	}
+	var n int
+	err := db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		n, err = rebuildIndexTx(tx)
+		return err
+	})
+	return n, err
+}
+
+func rebuildIndexTx(tx *bbolt.Tx) (int, error) {
+	if tx.Bucket([]byte(storage.INDEX_BUCKET)) != nil {
+		if err := tx.DeleteBucket([]byte(storage.INDEX_BUCKET)); err != nil {
+			return 0, err
+		}
+	}
+	idx, err := tx.CreateBucket([]byte(storage.INDEX_BUCKET))
+	if err != nil {
+		return 0, err
+	}
+	terms, err := idx.CreateBucket([]byte(termsBucket))
+	if err != nil {
+		return 0, err
+	}
+	docs, err := idx.CreateBucket([]byte(docsBucket))
+	if err != nil {
+		return 0, err
+	}
+
+	// collect the postings first, so each term is only written once
+	all := make(map[string]postings)
+	n := 0
+	err = forEachDocTx(tx, func(key string, t types.Task) error {
+		n++
+		freqs := termFreqs(t)
+		words := make([]string, 0, len(freqs))
+		for term, count := range freqs {
+			if all[term] == nil {
+				all[term] = make(postings)
+			}
+			all[term][key] = count
+			words = append(words, term)
+		}
+		sort.Strings(words)
+		data, err := json.Marshal(words)
+		if err != nil {
+			return err
+		}
+		return docs.Put([]byte(key), data)
+	})
+	if err != nil {
+		return 0, err
+	}
+	for term, p := range all {
+		if err := putPostings(terms, term, p); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// forEachDocTx calls fn with every active and archived task and its document key
+func forEachDocTx(tx *bbolt.Tx, fn func(key string, t types.Task) error) error {
+	if active := tx.Bucket([]byte(storage.ACTIVE_BUCKET)); active != nil {
+		err := active.ForEach(func(k, v []byte) error {
+			t, err := unpackTaskJson(v)
+			if err != nil {
+				return err
+			}
+			return fn(activeDocKey(string(k)), t)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	archive := tx.Bucket([]byte(storage.ARCHIVE_BUCKET))
+	if archive == nil {
+		return nil
+	}
+	return archive.ForEach(func(month, _ []byte) error {
+		monthBucket := archive.Bucket(month)
+		if monthBucket == nil {
+			return nil
+		}
+		return monthBucket.ForEach(func(k, v []byte) error {
+			t, err := unpackTaskJson(v)
+			if err != nil {
+				return err
+			}
+			return fn(archiveDocKey(string(month), string(k)), t)
+		})
+	})
+}
+
+// getDocTx loads the task stored under a document key, and whether it's archived
+func getDocTx(tx *bbolt.Tx, key string) (types.Task, bool, error) {
+	parts := strings.Split(key, "/")
+	var data []byte
+	switch {
+	case len(parts) == 2 && parts[0] == storage.ACTIVE_BUCKET:
+		if b := tx.Bucket([]byte(storage.ACTIVE_BUCKET)); b != nil {
+			data = b.Get([]byte(parts[1]))
+		}
+	case len(parts) == 3 && parts[0] == storage.ARCHIVE_BUCKET:
+		if archive := tx.Bucket([]byte(storage.ARCHIVE_BUCKET)); archive != nil {
+			if monthBucket := archive.Bucket([]byte(parts[1])); monthBucket != nil {
+				data = monthBucket.Get([]byte(parts[2]))
+			}
+		}
+	}
+	if data == nil {
+		return types.Task{}, false, errors.New("task not found: " + key)
+	}
+	t, err := unpackTaskJson(data)
+	return t, parts[0] == storage.ARCHIVE_BUCKET, err
+}
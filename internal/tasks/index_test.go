@@ -0,0 +1,65 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/webbben/task/internal/testutil"
+	"github.com/webbben/task/internal/types"
+)
+
+func TestTokenizeClipsLongWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Short words", []string{"short", "words"}},
+		{strings.Repeat("A", 100) + " end", []string{strings.Repeat("a", maxWordBytes), "end"}},
+		// é is two bytes, so clipping at an odd length would cut it in half
+		{"x" + strings.Repeat("é", 50), []string{"x" + strings.Repeat("é", (maxWordBytes-1)/2)}},
+	}
+	for _, tt := range tests {
+		got := tokenize(tt.in)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("tokenize(%.20q...) = %q, want %q", tt.in, got, tt.want)
+		}
+		for _, w := range got {
+			if len(w) > maxWordBytes || !utf8.ValidString(w) {
+				t.Errorf("tokenize(%.20q...) gave the word %q, which is %d bytes", tt.in, w, len(w))
+			}
+		}
+	}
+}
+
+func TestIndexLongWord(t *testing.T) {
+	testutil.OpenTempDatabase(t)
+
+	// longer than the largest key bbolt accepts
+	long := strings.Repeat("deadbeef", 5000)
+	task, err := CreateTask(types.Task{Title: "pasted hash", Description: "checksum " + long})
+	if err != nil {
+		t.Fatalf("CreateTask() with a %d byte word returned error: %v", len(long), err)
+	}
+	if _, err := RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex() returned error: %v", err)
+	}
+
+	for _, query := range []string{long, long[:100], "deadbeef*"} {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := Search(q, time.Now())
+		if err != nil {
+			t.Fatalf("Search(%.20q...) returned error: %v", query, err)
+		}
+		if len(results) != 1 || results[0].Task.ID != task.ID {
+			t.Errorf("Search(%.20q...) found %d tasks, want only %s", query, len(results), task.ID)
+		}
+		if got := Highlight(long, q, func(s string) string { return "[" + s + "]" }); got != "["+long+"]" {
+			t.Errorf("Highlight(%.20q...) didn't mark the long word", query)
+		}
+	}
+}
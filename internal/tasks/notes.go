@@ -0,0 +1,151 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// length of generated note IDs
+const noteIDLen = 8
+
+// AddNote adds a note to a task, and advances the task to in progress if it's still pending.
+// the title is optional; notes without one are shown by the time they were written.
+func AddNote(taskID, content, title string) (types.Note, error) {
+	now := time.Now()
+	note := types.Note{
+		Title:   title,
+		Content: content,
+		Created: now,
+		Edited:  now,
+	}
+	err := updateTask(taskID, func(t *types.Task) error {
+		note.ID = newNoteID(*t)
+		t.Notes = append(t.Notes, note)
+		if t.Status == constants.TaskStatus.Pending {
+			t.Status = constants.TaskStatus.InProgress
+		}
+		return nil
+	})
+	return note, err
+}
+
+// EditNote replaces the content of a note. The note can be given by its ID or a unique ID prefix.
+func EditNote(taskID, noteRef, content string) (types.Note, error) {
+	var note types.Note
+	err := updateTask(taskID, func(t *types.Task) error {
+		i, err := FindNote(*t, noteRef)
+		if err != nil {
+			return err
+		}
+		t.Notes[i].Content = content
+		t.Notes[i].Edited = time.Now()
+		note = t.Notes[i]
+		return nil
+	})
+	return note, err
+}
+
+// DeleteNote removes a note from a task. The note can be given by its ID or a unique ID prefix.
+func DeleteNote(taskID, noteRef string) (types.Note, error) {
+	var note types.Note
+	err := updateTask(taskID, func(t *types.Task) error {
+		i, err := FindNote(*t, noteRef)
+		if err != nil {
+			return err
+		}
+		note = t.Notes[i]
+		t.Notes = append(t.Notes[:i], t.Notes[i+1:]...)
+		return nil
+	})
+	return note, err
+}
+
+// MoveNote moves a note to another task, keeping its ID and times
+func MoveNote(fromTaskID, noteRef, toTaskID string) (types.Note, error) {
+	var note types.Note
+	if fromTaskID == toTaskID {
+		return note, errors.New("note is already on that task")
+	}
+
+	db := storage.DB()
+	if db == nil {
+		return note, errors.New("failed to get task database")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(storage.ACTIVE_BUCKET))
+		if b == nil {
+			return errors.New("failed to get task database")
+		}
+		from, err := getTaskTx(b, fromTaskID)
+		if err != nil {
+			return err
+		}
+		to, err := getTaskTx(b, toTaskID)
+		if err != nil {
+			return err
+		}
+		i, err := FindNote(from, noteRef)
+		if err != nil {
+			return err
+		}
+		note = from.Notes[i]
+		from.Notes = append(from.Notes[:i], from.Notes[i+1:]...)
+
+		// IDs are only unique per task
+		if _, err := FindNote(to, note.ID); err == nil {
+			note.ID = newNoteID(to)
+		}
+		// keep the notes in the order they were written
+		to.Notes = append(to.Notes, note)
+		sort.SliceStable(to.Notes, func(i, j int) bool { return to.Notes[i].Created.Before(to.Notes[j].Created) })
+
+		if err := putTaskTx(b, from); err != nil {
+			return err
+		}
+		return putTaskTx(b, to)
+	})
+	// note is only set once the update has run, so it can't be returned in the same statement
+	return note, err
+}
+
+// FindNote returns the index of the note with the given ID, or the only note whose ID starts with it
+func FindNote(t types.Task, noteRef string) (int, error) {
+	if noteRef == "" {
+		return -1, errors.New("note ID required")
+	}
+	match := -1
+	for i, n := range t.Notes {
+		if n.ID == noteRef {
+			return i, nil
+		}
+		if strings.HasPrefix(n.ID, noteRef) {
+			if match != -1 {
+				return -1, fmt.Errorf("note ID %q is ambiguous", noteRef)
+			}
+			match = i
+		}
+	}
+	if match == -1 {
+		return -1, fmt.Errorf("note not found: %s", noteRef)
+	}
+	return match, nil
+}
+
+func newNoteID(t types.Task) string {
+	for {
+		id := strings.ReplaceAll(uuid.New().String(), "-", "")[:noteIDLen]
+		if _, err := FindNote(t, id); err != nil {
+			return id
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package tasks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+// how well a title word matches a word of the query. A title that only contains the query's letters in order scores fuzzyMatch.
+const (
+	wordExact  = 6
+	wordPrefix = 4
+	wordInside = 2
+	fuzzyMatch = 1
+)
+
+// AmbiguousError is returned when a task reference matches several tasks
+type AmbiguousError struct {
+	Ref string
+	// Matches are the matching tasks, best match first
+	Matches []types.Task
+}
+
+func (e *AmbiguousError) Error() string {
+	lines := make([]string, len(e.Matches))
+	for i, t := range e.Matches {
+		lines[i] = fmt.Sprintf("  %s  %s", t.ID, util.Truncate(t.Title, 50))
+	}
+	return fmt.Sprintf("%q matches %d tasks; use more of the title, or the task ID:\n%s", e.Ref, len(e.Matches), strings.Join(lines, "\n"))
+}
+
+// ResolveTask finds the active task that ref refers to: its ID, a unique ID prefix, or words from its title.
+// If several tasks match, the error is an *AmbiguousError.
+func ResolveTask(ref string) (types.Task, error) {
+	t, _, err := ResolveTaskMatch(ref)
+	return t, err
+}
+
+// ResolveTaskMatch is ResolveTask, but also reports whether the task is certainly the one meant (see MatchTasks).
+// When it isn't, the task was the only one to partly match its title, so it should be confirmed before anything drastic is done to it.
+func ResolveTaskMatch(ref string) (t types.Task, certain bool, err error) {
+	all, err := GetAllTasks()
+	if err != nil {
+		return types.Task{}, false, err
+	}
+	matches, certain := MatchTasks(ref, all)
+	switch {
+	case len(matches) == 0:
+		return types.Task{}, false, fmt.Errorf("no active task has an ID or title matching %q", ref)
+	case len(matches) == 1 || certain:
+		return matches[0], certain, nil
+	}
+	return types.Task{}, false, &AmbiguousError{Ref: ref, Matches: matches}
+}
+
+// MatchTasks returns the tasks that ref could refer to, best match first. certain is set when the first match is
+// certainly the task meant: its ID is ref, it's the only task whose ID starts with ref, or its title is ref
+// (ignoring case) and no other title is.
+//
+// Tasks whose ID starts with ref are preferred. Otherwise titles are matched by their words: each word of ref
+// has to be a word of the title, the start of one, or part of one. Failing that, the letters of ref have to appear
+// in the title in order, so "dply fx" finds "Deploy fix".
+func MatchTasks(ref string, all []types.Task) (matches []types.Task, certain bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return all, false
+	}
+	for _, t := range all {
+		if t.ID == ref {
+			return []types.Task{t}, true
+		}
+		if strings.HasPrefix(t.ID, ref) {
+			matches = append(matches, t)
+		}
+	}
+	if len(matches) > 0 {
+		return matches, len(matches) == 1
+	}
+
+	scores := make(map[string]int)
+	exactTitles := 0
+	for _, t := range all {
+		score := titleScore(ref, t.Title)
+		if score == 0 {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(t.Title), ref) {
+			exactTitles++
+			// an exact title always comes first
+			score += 1000
+		}
+		scores[t.ID] = score
+		matches = append(matches, t)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if scores[matches[i].ID] != scores[matches[j].ID] {
+			return scores[matches[i].ID] > scores[matches[j].ID]
+		}
+		return matches[i].LastUpdate.After(matches[j].LastUpdate)
+	})
+	return matches, exactTitles == 1
+}
+
+// titleScore scores how well a title matches ref, or 0 if it doesn't
+func titleScore(ref, title string) int {
+	titleWords := tokenize(title)
+	score := 0
+	for _, w := range tokenize(ref) {
+		best := 0
+		for _, tw := range titleWords {
+			switch {
+			case tw == w:
+				best = max(best, wordExact)
+			case strings.HasPrefix(tw, w):
+				best = max(best, wordPrefix)
+			case strings.Contains(tw, w):
+				best = max(best, wordInside)
+			}
+		}
+		if best == 0 {
+			score = 0
+			break
+		}
+		score += best
+	}
+	if score > 0 {
+		return score
+	}
+	if isSubsequence(strings.Join(tokenize(ref), ""), strings.Join(titleWords, "")) {
+		return fuzzyMatch
+	}
+	return 0
+}
+
+func isSubsequence(s, of string) bool {
+	if s == "" {
+		return false
+	}
+	rest := []rune(s)
+	for _, r := range of {
+		if r == rest[0] {
+			rest = rest[1:]
+			if len(rest) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
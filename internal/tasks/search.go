@@ -0,0 +1,279 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/types"
+	"go.etcd.io/bbolt"
+)
+
+// a task's score is boosted by up to this factor when it was just updated, and the boost halves every recencyHalfLife
+const (
+	recencyBoost    = 1.0
+	recencyHalfLife = 30 * 24 * time.Hour
+)
+
+// Query is a parsed search query. Every word and phrase has to match for a task to be found.
+type Query struct {
+	words   []queryWord
+	phrases [][]string
+}
+
+type queryWord struct {
+	text   string
+	prefix bool
+}
+
+// matches reports whether a lowercase word from a task matches the query word
+func (w queryWord) matches(word string) bool {
+	if w.prefix {
+		return strings.HasPrefix(word, w.text)
+	}
+	return word == w.text
+}
+
+// ParseQuery parses a search query made of words, "quoted phrases" and prefixes like migrat*.
+// Punctuation splits words, so a word like "follow-up" is searched as a phrase.
+func ParseQuery(s string) (Query, error) {
+	var q Query
+	for i, part := range strings.Split(s, `"`) {
+		// every other part is inside quotes; an unclosed quote runs to the end of the query
+		if i%2 == 1 {
+			q.addPhrase(tokenize(part), false)
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			q.addPhrase(tokenize(field), strings.HasSuffix(field, "*"))
+		}
+	}
+	if len(q.words) == 0 {
+		return q, errors.New("the search query has no words to search for")
+	}
+	return q, nil
+}
+
+// addPhrase adds the words of a phrase to the query. The last word is a prefix when prefix is set.
+func (q *Query) addPhrase(words []string, prefix bool) {
+	for i, w := range words {
+		qw := queryWord{text: w, prefix: prefix && i == len(words)-1}
+		if !slices.Contains(q.words, qw) {
+			q.words = append(q.words, qw)
+		}
+	}
+	if len(words) > 1 && !prefix {
+		q.phrases = append(q.phrases, words)
+	}
+}
+
+// Matches reports whether a word matches one of the query's words, ignoring case
+func (q Query) Matches(word string) bool {
+	word = normalizeWord(word)
+	for _, w := range q.words {
+		if w.matches(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// phrasesMatch reports whether each of the query's phrases appears in one of the task's fields
+func (q Query) phrasesMatch(t types.Task) bool {
+	fields := searchFields(t)
+	for _, phrase := range q.phrases {
+		found := false
+		for _, field := range fields {
+			if containsPhrase(tokenize(field), phrase) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(phrase)], phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchResult is a task found by a search
+type SearchResult struct {
+	Task types.Task
+	// Archived is set for completed tasks, whose ID can't be used with other commands since it's no longer active
+	Archived bool
+	Score    float64
+}
+
+// Search finds the active and archived tasks matching the query, most relevant first.
+// Relevance is how often the query's words appear in a task (more so in its title), weighted by how rare the words are,
+// and boosted for recently updated tasks.
+func Search(q Query, now time.Time) ([]SearchResult, error) {
+	db := storage.DB()
+	if db == nil {
+		return nil, errors.New("failed to get task database")
+	}
+
+	var results []SearchResult
+	err := db.View(func(tx *bbolt.Tx) error {
+		idx := tx.Bucket([]byte(storage.INDEX_BUCKET))
+		if idx == nil {
+			return ErrNoIndex
+		}
+		terms, docs := idx.Bucket([]byte(termsBucket)), idx.Bucket([]byte(docsBucket))
+		if terms == nil || docs == nil {
+			return ErrNoIndex
+		}
+		total := docs.Stats().KeyN
+
+		var scores map[string]float64
+		for _, w := range q.words {
+			matched, err := scoreWord(terms, w, total)
+			if err != nil {
+				return err
+			}
+			if scores == nil {
+				scores = matched
+				continue
+			}
+			for key := range scores {
+				if s, ok := matched[key]; ok {
+					scores[key] += s
+				} else {
+					delete(scores, key)
+				}
+			}
+		}
+
+		for key, score := range scores {
+			t, archived, err := getDocTx(tx, key)
+			if err != nil {
+				// the index is out of date if an older version of task changed the database; "task reindex" fixes it
+				continue
+			}
+			if !q.phrasesMatch(t) {
+				continue
+			}
+			age := max(now.Sub(t.LastUpdate), 0)
+			score *= 1 + recencyBoost*math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+			results = append(results, SearchResult{Task: t, Archived: archived, Score: score})
+		}
+		return nil
+	})
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Task.LastUpdate.After(results[j].Task.LastUpdate)
+	})
+	return results, err
+}
+
+// scoreWord scores the documents containing a query word. A prefix matches every indexed word starting with it.
+func scoreWord(terms *bbolt.Bucket, w queryWord, total int) (map[string]float64, error) {
+	scores := make(map[string]float64)
+	add := func(data []byte) error {
+		p := make(postings)
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		// rare words say more about a task than common ones
+		idf := math.Log(1 + float64(total)/float64(len(p)))
+		for key, n := range p {
+			scores[key] += (1 + math.Log(float64(n))) * idf
+		}
+		return nil
+	}
+
+	if !w.prefix {
+		if data := terms.Get([]byte(w.text)); data != nil {
+			return scores, add(data)
+		}
+		return scores, nil
+	}
+	c := terms.Cursor()
+	prefix := []byte(w.text)
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if err := add(v); err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+// Highlight marks each word of text that matches the query with mark
+func Highlight(text string, q Query, mark func(string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, span := range wordSpans(text) {
+		word := text[span[0]:span[1]]
+		if !q.Matches(word) {
+			continue
+		}
+		sb.WriteString(text[last:span[0]])
+		sb.WriteString(mark(word))
+		last = span[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// Snippet returns up to width characters around the first match of the query in the task's description or notes,
+// on a single line. It's empty when the query only matches the title.
+func Snippet(t types.Task, q Query, width int) string {
+	for _, field := range searchFields(t)[1:] {
+		text := strings.Join(strings.Fields(field), " ")
+		pos := firstMatch(text, q)
+		if pos < 0 {
+			continue
+		}
+		runes := []rune(text)
+		at := utf8.RuneCountInString(text[:pos])
+		// show a bit of context before the match
+		from := max(at-width/3, 0)
+		to := min(from+width, len(runes))
+		from = max(to-width, 0)
+		// don't cut words in half at the edges
+		for from > 0 && from < at && runes[from-1] != ' ' {
+			from++
+		}
+		for to < len(runes) && to > at && runes[to] != ' ' {
+			to--
+		}
+		snippet := strings.TrimSpace(string(runes[from:to]))
+		if from > 0 {
+			snippet = "…" + snippet
+		}
+		if to < len(runes) {
+			snippet += "…"
+		}
+		return snippet
+	}
+	return ""
+}
+
+// firstMatch returns the byte offset of the first word in text matching the query, or -1
+func firstMatch(text string, q Query) int {
+	for _, span := range wordSpans(text) {
+		if q.Matches(text[span[0]:span[1]]) {
+			return span[0]
+		}
+	}
+	return -1
+}
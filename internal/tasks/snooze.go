@@ -0,0 +1,24 @@
+package tasks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/types"
+)
+
+// SnoozeTask hides a task from the task list until the given time. A zero time wakes the task up again.
+func SnoozeTask(taskID string, until time.Time) error {
+	return updateTask(taskID, func(t *types.Task) error {
+		if until.IsZero() {
+			t.WaitUntil = nil
+			return nil
+		}
+		if t.Status == constants.TaskStatus.Complete {
+			return errors.New("task is already complete")
+		}
+		t.WaitUntil = &until
+		return nil
+	})
+}
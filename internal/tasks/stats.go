@@ -0,0 +1,143 @@
+package tasks
+
+import (
+	"sort"
+	"time"
+
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+// Stats are productivity statistics over a number of weeks
+type Stats struct {
+	Since     time.Time   `json:"since"`
+	Weeks     []WeekStats `json:"weeks"`
+	Created   int         `json:"created"`
+	Completed int         `json:"completed"`
+	// MedianCycleHours is the median time from adding a task to completing it. It's 0 if no completed task has a known creation time.
+	MedianCycleHours float64 `json:"median_cycle_hours"`
+	// OverdueRate is the share of completed tasks that were completed after their due date, from 0 to 1
+	OverdueRate float64         `json:"overdue_rate"`
+	Categories  []CategoryStats `json:"categories"`
+	// Undated is the number of tasks with no creation time, since they were added by an older version. They aren't counted
+	// in Created or the cycle times, but are counted as open from the earliest time they were seen.
+	Undated int `json:"undated"`
+}
+
+// WeekStats counts the tasks added and completed in a week, and the tasks still open at the end of it
+type WeekStats struct {
+	Start     time.Time `json:"start"`
+	Created   int       `json:"created"`
+	Completed int       `json:"completed"`
+	Open      int       `json:"open"`
+}
+
+// CategoryStats is the throughput of a category: how many of its tasks were completed, and how quickly
+type CategoryStats struct {
+	Category         string  `json:"category"`
+	Completed        int     `json:"completed"`
+	PerWeek          float64 `json:"per_week"`
+	MedianCycleHours float64 `json:"median_cycle_hours"`
+}
+
+// ComputeStats calculates the statistics for the weeks from since until now. all should hold the active tasks and
+// the tasks completed since then; a task's completion time is its last update.
+func ComputeStats(all []types.Task, since, now time.Time, weekStart time.Weekday) Stats {
+	// an empty list rather than null in JSON, when nothing was completed
+	s := Stats{Since: since, Categories: []CategoryStats{}}
+	for start := since; start.Before(now); start = start.AddDate(0, 0, 7) {
+		s.Weeks = append(s.Weeks, WeekStats{Start: start})
+	}
+	weekOf := func(t time.Time) int {
+		if t.Before(since) || t.After(now) {
+			return -1
+		}
+		return int(util.StartOfWeek(t, weekStart).Sub(since).Hours()/24+0.5) / 7
+	}
+
+	var cycles []time.Duration
+	completedByCategory := make(map[string]int)
+	cycleByCategory := make(map[string][]time.Duration)
+	overdue := 0
+	for _, t := range all {
+		created := t.CreatedAt()
+		// CreatedAt guesses for older tasks, which would skew the added counts and cycle times
+		dated := !t.Created.IsZero()
+		if !dated {
+			s.Undated++
+		} else if w := weekOf(created); w >= 0 && w < len(s.Weeks) {
+			s.Weeks[w].Created++
+			s.Created++
+		}
+		complete := t.Status == constants.TaskStatus.Complete
+		// the task is open from when it was added until it was completed
+		for i := range s.Weeks {
+			end := s.Weeks[i].Start.AddDate(0, 0, 7)
+			if created.Before(end) && (!complete || !t.LastUpdate.Before(end)) {
+				s.Weeks[i].Open++
+			}
+		}
+
+		if !complete {
+			continue
+		}
+		w := weekOf(t.LastUpdate)
+		if w < 0 || w >= len(s.Weeks) {
+			continue
+		}
+		s.Weeks[w].Completed++
+		s.Completed++
+		completedByCategory[t.Category]++
+		if dated {
+			cycle := t.LastUpdate.Sub(created)
+			cycles = append(cycles, cycle)
+			cycleByCategory[t.Category] = append(cycleByCategory[t.Category], cycle)
+		}
+		if completedLate(t) {
+			overdue++
+		}
+	}
+
+	s.MedianCycleHours = median(cycles).Hours()
+	if s.Completed > 0 {
+		s.OverdueRate = float64(overdue) / float64(s.Completed)
+	}
+	for category, n := range completedByCategory {
+		s.Categories = append(s.Categories, CategoryStats{
+			Category:         category,
+			Completed:        n,
+			PerWeek:          float64(n) / float64(max(len(s.Weeks), 1)),
+			MedianCycleHours: median(cycleByCategory[category]).Hours(),
+		})
+	}
+	sort.Slice(s.Categories, func(i, j int) bool {
+		if s.Categories[i].Completed != s.Categories[j].Completed {
+			return s.Categories[i].Completed > s.Categories[j].Completed
+		}
+		return s.Categories[i].Category < s.Categories[j].Category
+	})
+	return s
+}
+
+// completedLate returns true if the task was completed after its due time, or after its due day for date-only due dates
+func completedLate(t types.Task) bool {
+	deadline := t.Due()
+	if !t.DueHasTime {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	return t.LastUpdate.After(deadline)
+}
+
+func median(d []time.Duration) time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), d...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
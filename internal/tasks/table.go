@@ -0,0 +1,439 @@
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/fatih/color"
+	"github.com/webbben/task/internal/calendar"
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+// Column describes a column of the task table
+type Column struct {
+	Name   string // the name used to choose the column, e.g. in --columns
+	Header string
+	// MinWidth is the narrowest the column can be.
+	MinWidth int
+	// MaxWidth is the widest the column can be. 0 means the column takes up any space left over by the others.
+	MaxWidth int
+	// Priority decides which columns are dropped first when the terminal is too narrow (lowest goes first)
+	Priority int
+	// Cut makes values that are too long get cut off, instead of truncated with "..."
+	Cut   bool
+	Value func(t types.Task) string
+}
+
+var columns = []Column{
+	{Name: "id", Header: "ID", MinWidth: 8, MaxWidth: 8, Priority: 100, Cut: true,
+		Value: func(t types.Task) string { return t.ID }},
+	{Name: "title", Header: "Title", MinWidth: 18, Priority: 90,
+		Value: func(t types.Task) string { return t.Title }},
+	{Name: "cat", Header: "Cat.", MinWidth: 6, MaxWidth: 12, Priority: 40,
+		Value: func(t types.Task) string { return t.Category }},
+	{Name: "due", Header: "Due Date", MinWidth: 10, MaxWidth: 16, Priority: 80,
+		Value: func(t types.Task) string { return formatDate(t, t.Status == constants.TaskStatus.Complete) }},
+	{Name: "status", Header: "Status", MinWidth: 8, MaxWidth: 8, Priority: 70,
+		Value: func(t types.Task) string { return formatStatus(t.Status) }},
+	{Name: "pr", Header: "Pr.", MinWidth: 3, MaxWidth: 3, Priority: 10,
+		Value: func(t types.Task) string { return fmt.Sprintf("%d", t.Priority) }},
+	{Name: "upd", Header: "Upd.", MinWidth: 4, MaxWidth: 4, Priority: 20,
+		Value: func(t types.Task) string { return timeSinceDateFormat(t.LastUpdate) }},
+	{Name: "tags", Header: "Tags", MinWidth: 6, MaxWidth: 20, Priority: 30,
+		Value: func(t types.Task) string { return strings.Join(t.Tags, ",") }},
+	{Name: "wait", Header: "Wait", MinWidth: 5, MaxWidth: 10, Priority: 14,
+		Value: func(t types.Task) string {
+			if !t.Waiting(time.Now()) {
+				return ""
+			}
+			return t.WaitUntil.Local().Format(dateFormat)
+		}},
+	{Name: "urg", Header: "Urg.", MinWidth: 4, MaxWidth: 5, Priority: 50,
+		Value: func(t types.Task) string { return fmt.Sprintf("%.1f", UrgencyScore(t)) }},
+	{Name: "est", Header: "Est.", MinWidth: 4, MaxWidth: 7, Priority: 16,
+		Value: func(t types.Task) string {
+			if t.Estimate == nil {
+				return ""
+			}
+			return t.Estimate.String()
+		}},
+	{Name: "spent", Header: "Spent", MinWidth: 5, MaxWidth: 8, Priority: 15,
+		Value: formatSpent},
+	{Name: "ws", Header: "WS", MinWidth: 8, MaxWidth: 12, Priority: 60,
+		Value: func(t types.Task) string { return t.Workspace }},
+}
+
+var (
+	// colors for due dates
+	veryLate = color.New(color.BgRed)
+	late     = color.New(color.FgHiRed)
+	today    = color.New(color.FgHiYellow)
+	tomorrow = color.New(color.FgCyan)
+
+	// status colors
+	comp = color.New(color.BgGreen, color.FgBlack)
+	prog = color.New(color.FgCyan)
+
+	borderColor = color.New(color.FgHiBlack)
+
+	// Go time layout used for due dates
+	dateFormat = "1-2"
+	// working days used to tell how late a task is
+	workCalendar = calendar.Standard()
+
+	themeLoaded = false
+)
+
+// formatSpent shows the time logged on a task, marked with a * while its timer is running
+func formatSpent(t types.Task) string {
+	if len(t.TimeLog) == 0 {
+		return ""
+	}
+	spent := util.FormatDuration(t.TimeSpent())
+	if t.TimerRunning() {
+		spent += "*"
+	}
+	return spent
+}
+
+// ColumnNames returns the names of all columns that can be shown in the task table
+func ColumnNames() []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseColumns parses a comma separated list of column names, e.g. "id,title,due".
+// Column widths from the config are applied to the returned columns.
+func ParseColumns(spec string) ([]Column, error) {
+	widths := config.Get().ColumnWidths
+	out := make([]Column, 0)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		col, ok := findColumn(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (valid columns: %s)", name, strings.Join(ColumnNames(), ", "))
+		}
+		if w, ok := widths[name]; ok {
+			col.MinWidth = w
+			if col.MaxWidth != 0 {
+				col.MaxWidth = w
+			}
+		}
+		out = append(out, col)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return out, nil
+}
+
+// DefaultColumns returns the columns set in the config
+func DefaultColumns() []Column {
+	cols, err := ParseColumns(strings.Join(config.Get().Columns, ","))
+	if err != nil {
+		log.Println("invalid columns in config:", err)
+		cols, _ = ParseColumns(strings.Join(config.Default().Columns, ","))
+	}
+	return cols
+}
+
+// WithColumn returns the given columns with the named column inserted after the column named after,
+// unless it's already there. If after isn't found, the column goes at the start.
+func WithColumn(cols []Column, name, after string) []Column {
+	col, ok := findColumn(name)
+	if !ok {
+		return cols
+	}
+	i := 0
+	for j, c := range cols {
+		if c.Name == name {
+			return cols
+		}
+		if c.Name == after {
+			i = j + 1
+		}
+	}
+	out := make([]Column, 0, len(cols)+1)
+	out = append(out, cols[:i]...)
+	out = append(out, col)
+	return append(out, cols[i:]...)
+}
+
+func findColumn(name string) (Column, bool) {
+	for _, c := range columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// loadTheme sets up the colors and date format from the config
+func loadTheme() {
+	if themeLoaded {
+		return
+	}
+	themeLoaded = true
+	cfg := config.Get()
+
+	veryLate = config.ColorOrNone(cfg.Theme.VeryLate)
+	late = config.ColorOrNone(cfg.Theme.Late)
+	today = config.ColorOrNone(cfg.Theme.Today)
+	tomorrow = config.ColorOrNone(cfg.Theme.Tomorrow)
+	comp = config.ColorOrNone(cfg.Theme.Complete)
+	prog = config.ColorOrNone(cfg.Theme.InProgress)
+	borderColor = config.ColorOrNone(cfg.Theme.Border)
+	dateFormat = cfg.DateFormat
+	workCalendar = calendar.FromConfig()
+}
+
+// PrintListOfTasks prints a list of tasks in a formatted table, using the columns from the config
+func PrintListOfTasks(tasks []types.Task) {
+	PrintTable(tasks, DefaultColumns())
+}
+
+// PrintTable prints a list of tasks in a formatted table with the given columns.
+//
+// If the terminal is too narrow to fit all the columns, the lowest priority columns are left out.
+func PrintTable(tasks []types.Task, cols []Column) {
+	loadTheme()
+
+	width, _, err := term.GetSize(os.Stdin.Fd())
+	if err != nil {
+		log.Println("failed to get terminal size:", err)
+		width = 80
+	}
+	writeTable(os.Stdout, tasks, cols, width)
+}
+
+// writeTable writes the task table to w, laid out to fill the given width
+func writeTable(w io.Writer, tasks []types.Task, cols []Column, width int) {
+	totalWidth := width - 2 // make space for the borders on the sides
+
+	// get all the values up front, so columns only grow as wide as their content needs
+	rows := make([][]string, len(tasks))
+	contentWidths := make([]int, len(cols))
+	for i, task := range tasks {
+		rows[i] = make([]string, len(cols))
+		for j, col := range cols {
+			rows[i][j] = col.Value(task)
+			contentWidths[j] = max(contentWidths[j], util.Width(rows[i][j]))
+		}
+	}
+	keep := layoutColumns(cols, contentWidths, totalWidth)
+
+	// Create top border, header separator, and bottom border with lighter color
+	topBorder := borderColor.Sprintf("┌%s┐\n", strings.Repeat("─", totalWidth))
+	headerSeparator := borderColor.Sprintf("├%s┤\n", strings.Repeat("─", totalWidth))
+	bottomBorder := borderColor.Sprintf("└%s┘\n", strings.Repeat("─", totalWidth))
+
+	printRow := func(values []string) {
+		fmt.Fprint(w, borderColor.Sprintf("│"))
+		for i, value := range values {
+			fmt.Fprintf(w, " %s", value)
+			if i < len(values)-1 {
+				fmt.Fprint(w, "  ")
+			}
+		}
+		fmt.Fprint(w, borderColor.Sprintf(" │\n"))
+	}
+
+	fmt.Fprint(w, topBorder)
+
+	headers := make([]string, 0, len(keep))
+	for _, k := range keep {
+		headers = append(headers, util.PadRight(util.Truncate(cols[k.index].Header, k.width), k.width))
+	}
+	printRow(headers)
+	fmt.Fprint(w, headerSeparator)
+
+	for _, row := range rows {
+		values := make([]string, 0, len(keep))
+		for _, k := range keep {
+			value := row[k.index]
+			if cols[k.index].Cut {
+				value = util.Cut(value, k.width)
+			} else {
+				value = util.Truncate(value, k.width)
+			}
+			// pad by display width, since colors and wide characters make the byte length misleading
+			values = append(values, util.PadRight(value, k.width))
+		}
+		printRow(values)
+	}
+
+	fmt.Fprint(w, bottomBorder)
+}
+
+// columnLayout is the width decided for one of the columns given to layoutColumns
+type columnLayout struct {
+	index int // index of the column in the given columns
+	width int
+}
+
+// rowWidth returns the width taken up by a row with the given column layout, not including the side borders
+func rowWidth(layout []columnLayout) int {
+	total := 1 // space before the right border
+	for i, l := range layout {
+		total += l.width + 1 // space before each column
+		if i < len(layout)-1 {
+			total += 2 // gap between each column
+		}
+	}
+	return total
+}
+
+// layoutColumns decides which columns to show and how wide each one is, so that the table fills the given width.
+//
+// Columns are dropped, lowest priority first, until the rest fit at their minimum width. Then columns with a max width
+// grow to fit their content (contentWidths) up to that max, and the columns without a max width share the rest.
+func layoutColumns(cols []Column, contentWidths []int, width int) []columnLayout {
+	layout := make([]columnLayout, len(cols))
+	for i, c := range cols {
+		layout[i] = columnLayout{index: i, width: c.MinWidth}
+	}
+	for len(layout) > 1 && rowWidth(layout) > width {
+		lowest := 0
+		for i, l := range layout {
+			if cols[l.index].Priority <= cols[layout[lowest].index].Priority {
+				lowest = i
+			}
+		}
+		layout = append(layout[:lowest], layout[lowest+1:]...)
+	}
+
+	extra := width - rowWidth(layout)
+
+	// grow bounded columns to fit their content, highest priority first
+	order := make([]int, len(layout))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return cols[layout[order[a]].index].Priority > cols[layout[order[b]].index].Priority
+	})
+	for _, i := range order {
+		c := cols[layout[i].index]
+		if extra <= 0 {
+			break
+		}
+		target := min(c.MaxWidth, contentWidths[layout[i].index])
+		if target > layout[i].width {
+			grow := min(extra, target-layout[i].width)
+			layout[i].width += grow
+			extra -= grow
+		}
+	}
+
+	// split what's left between the flexible columns
+	flexible := make([]int, 0)
+	for i, l := range layout {
+		if cols[l.index].MaxWidth == 0 {
+			flexible = append(flexible, i)
+		}
+	}
+	if len(flexible) == 0 && len(layout) > 0 {
+		// nothing can grow, so pad out the last column to keep the borders lined up
+		flexible = append(flexible, len(layout)-1)
+	}
+	for n, i := range flexible {
+		if extra <= 0 {
+			break
+		}
+		share := extra / (len(flexible) - n)
+		layout[i].width += share
+		extra -= share
+	}
+
+	return layout
+}
+
+// FormatDue formats the task's due date the same way as the due column, colored by how late it is
+func FormatDue(t types.Task) string {
+	loadTheme()
+	return formatDate(t, t.Status == constants.TaskStatus.Complete)
+}
+
+// formatDate formats the task's due date with the configured date format (M-D by default). If year is not current, also shows year at the end in parentheses.
+// The time of day is added for tasks due at a set time.
+//
+// How late a task is counts working days, so a task due on Friday is only a day late on Monday.
+func formatDate(task types.Task, skipColor bool) string {
+	date := task.Due()
+	now := time.Now()
+
+	out := date.Format(dateFormat)
+	if date.Year() != now.Year() && !strings.Contains(dateFormat, "06") {
+		out += "-" + date.Format("2006")
+	}
+	if task.DueHasTime {
+		out += " " + date.Format("15:04")
+	}
+
+	if !skipColor {
+		// working days from the due date to today; negative if the task is due in the future
+		workDaysLate := workCalendar.WorkDaysBetween(date, now)
+		dueDay := util.RoundDateDown(date)
+		todayStart := util.RoundDateDown(now)
+		switch {
+		case workDaysLate >= 2:
+			out = veryLate.Sprint(out)
+		case workDaysLate == 1:
+			out = late.Sprint(out)
+		// a task due at a time of day is late as soon as that time has passed
+		case task.DueHasTime && date.Before(now):
+			out = late.Sprint(out)
+		case !dueDay.After(todayStart):
+			out = today.Sprint(out)
+		// due on the next working day, e.g. on Monday when it's Friday
+		case workDaysLate == -1 && workCalendar.IsWorkDay(dueDay), workDaysLate == 0:
+			out = tomorrow.Sprint(out)
+		}
+	}
+
+	return out
+}
+
+// timeSinceDateFormat returns the number of days, weeks, or months since the given date.
+//
+// the string is formatted as a number followed by a letter which represents the unit ("d", "w", or "m").
+func timeSinceDateFormat(date time.Time) string {
+	duration := time.Since(date)
+
+	days := int(duration.Hours() / 24)
+	if days < 14 {
+		return fmt.Sprintf("%vd", days)
+	}
+	weeks := days / 7
+	if weeks < 8 {
+		return fmt.Sprintf("%vw", weeks)
+	}
+	months := days / 30
+	return fmt.Sprintf("%vm", months)
+}
+
+func formatStatus(status int) string {
+	out := constants.TaskStatusDisplay[status]
+	if status == constants.TaskStatus.Complete {
+		out = comp.Sprint(out)
+	} else if status == constants.TaskStatus.InProgress {
+		out = prog.Sprint(out)
+	}
+	return out
+}
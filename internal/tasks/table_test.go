@@ -0,0 +1,53 @@
+package tasks
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/webbben/task/internal/testutil"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+func TestWriteTable(t *testing.T) {
+	color.NoColor = true
+
+	tableTasks := []types.Task{
+		{ID: "deployfix1a2b", Title: "deploy the fix", Category: "ops", Tags: []string{"urgent"}, Priority: 2},
+		{ID: "nihongo3c4d", Title: "日本語のタスクタイトルをテストする", Category: "翻訳", Priority: 1},
+		{ID: "pairing5e6f", Title: "👩‍💻 pair on the 👨‍👩‍👧 family demo", Tags: []string{"🇯🇵", "demo"}},
+		{ID: "cafe7a8b", Title: "café déjà vu: review the résumé drafts", Category: "hiring"},
+	}
+	status := Column{Name: "state", Header: "State", MinWidth: 6, MaxWidth: 8, Priority: 70,
+		Value: func(t types.Task) string {
+			if t.Priority > 1 {
+				return "\x1b[31mlate\x1b[0m"
+			}
+			return "\x1b[32mok\x1b[0m"
+		}}
+	var cols []Column
+	for _, name := range []string{"id", "title", "cat", "tags", "pr"} {
+		col, ok := findColumn(name)
+		if !ok {
+			t.Fatalf("no %s column", name)
+		}
+		cols = append(cols, col)
+	}
+	cols = append(cols[:2], append([]Column{status}, cols[2:]...)...)
+
+	var out bytes.Buffer
+	for _, width := range []int{100, 72, 50, 36} {
+		var table bytes.Buffer
+		writeTable(&table, tableTasks, cols, width)
+		for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+			if util.Width(line) != width {
+				t.Errorf("table line is %d wide instead of %d: %q", util.Width(line), width, line)
+			}
+		}
+		fmt.Fprintf(&out, "# width %d\n%s", width, table.String())
+	}
+	testutil.CheckGolden(t, "table", strings.ReplaceAll(out.String(), "\x1b", `\e`))
+}
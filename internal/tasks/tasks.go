@@ -5,56 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
 	"regexp"
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/x/term"
-	"github.com/fatih/color"
 	"github.com/google/uuid"
 	"github.com/webbben/task/internal/constants"
 	"github.com/webbben/task/internal/storage"
 	"github.com/webbben/task/internal/types"
-	"github.com/webbben/task/internal/util"
 	"go.etcd.io/bbolt"
 )
 
-const (
-	colID         = "ID"
-	colTitle      = "Title"
-	colCategory   = "Cat."
-	colDueDate    = "Due Date"
-	colStatus     = "Status"
-	colPriority   = "Pr."
-	colLastUpdate = "Upd."
-)
-
-var (
-	// colors for due dates
-	veryLate = color.New(color.BgRed)
-	late     = color.New(color.FgHiRed)
-	today    = color.New(color.FgHiYellow)
-	tomorrow = color.New(color.FgCyan)
-
-	// status colors
-	comp = color.New(color.BgGreen, color.FgBlack)
-	prog = color.New(color.FgCyan)
-
-	// columns that will be displayed in the table
-	headers = []string{colID, colTitle, colDueDate, colStatus, colPriority, colLastUpdate}
-
-	// widths for each column type
-	colWidths = map[string]int{
-		colID:         8,
-		colTitle:      18,
-		colCategory:   6,
-		colDueDate:    10,
-		colStatus:     8,
-		colPriority:   3,
-		colLastUpdate: 4,
-	}
-)
+// length of generated task IDs
+const maxIDLen = 12
 
 // generates an ID that includes the task title as much as possible.
 // doing this so it's easy to predict the task ID when typing them in the CLI, since it's a lot
@@ -65,7 +28,6 @@ var (
 //
 // pass in an empty string to get a random ID that isn't based on any title text.
 func GenerateTaskID(title string) string {
-	maxIDLen := 12
 	re := regexp.MustCompile(`[^a-zA-Z0-9]+`)
 	formatted := strings.ToLower(re.ReplaceAllString(title, ""))
 	genID := strings.ReplaceAll(uuid.New().String(), "-", "")
@@ -109,17 +71,24 @@ func idAlreadyUsed(id string) (bool, error) {
 	return inUse, err
 }
 
-// AddTask creates a new task and stores it in the database
+// AddTask creates a new task, due on the date of dueDate, and stores it in the database
 func AddTask(title, description, category string, dueDate time.Time) (types.Task, error) {
 	task := types.Task{
-		ID:          GenerateTaskID(title),
 		Title:       title,
 		Description: description,
 		Category:    category,
-		DueDate:     dueDate,
-		Status:      constants.TaskStatus.Pending,
-		LastUpdate:  time.Now(),
 	}
+	task.SetDue(dueDate, false)
+	return CreateTask(task)
+}
+
+// CreateTask stores a new task in the database. The ID, status and last update time are filled in,
+// so only the task's content needs to be set.
+func CreateTask(task types.Task) (types.Task, error) {
+	task.ID = GenerateTaskID(task.Title)
+	task.Status = constants.TaskStatus.Pending
+	task.LastUpdate = time.Now()
+	task.Created = task.LastUpdate
 
 	db := storage.DB()
 	if db == nil {
@@ -132,11 +101,15 @@ func AddTask(title, description, category string, dueDate time.Time) (types.Task
 		if err != nil {
 			return err
 		}
-		return b.Put([]byte(task.ID), data)
+		if err := b.Put([]byte(task.ID), data); err != nil {
+			return err
+		}
+		return indexTaskTx(tx, activeDocKey(task.ID), task)
 	})
 }
 
-func AddNote(taskID, note, noteName string) error {
+// UpdateTask saves changes to an existing active task
+func UpdateTask(task types.Task) error {
 	db := storage.DB()
 	if db == nil {
 		return errors.New("failed to get task database")
@@ -147,30 +120,79 @@ func AddNote(taskID, note, noteName string) error {
 		if b == nil {
 			return errors.New("failed to get task database")
 		}
-		data := b.Get([]byte(taskID))
-		var t types.Task
-		if err := json.Unmarshal(data, &t); err != nil {
-			return err
+		if b.Get([]byte(task.ID)) == nil {
+			return fmt.Errorf("task not found: %s", task.ID)
 		}
-		if t.Notes == nil {
-			t.Notes = make(map[string]string)
+		task.LastUpdate = time.Now()
+		data, err := json.Marshal(task)
+		if err != nil {
+			return err
 		}
-		t.Notes[noteName] = note
-		t.LastUpdate = time.Now()
-		// advance to in progress if its still pending
-		if t.Status == constants.TaskStatus.Pending {
-			t.Status = constants.TaskStatus.InProgress
+		if err := b.Put([]byte(task.ID), data); err != nil {
+			return err
 		}
+		return indexTaskTx(tx, activeDocKey(task.ID), task)
+	})
+}
+
+// updateTask loads the task, applies the change and saves it back
+func updateTask(taskID string, change func(t *types.Task) error) error {
+	db := storage.DB()
+	if db == nil {
+		return errors.New("failed to get task database")
+	}
 
-		// put back into json and put back into db
-		data, err := json.Marshal(t)
+	return db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(storage.ACTIVE_BUCKET))
+		if b == nil {
+			return errors.New("failed to get task database")
+		}
+		t, err := getTaskTx(b, taskID)
 		if err != nil {
 			return err
 		}
-		return b.Put([]byte(taskID), data)
+		if err := change(&t); err != nil {
+			return err
+		}
+		return putTaskTx(b, t)
 	})
 }
 
+func getTaskTx(b *bbolt.Bucket, taskID string) (types.Task, error) {
+	var t types.Task
+	data := b.Get([]byte(taskID))
+	if data == nil {
+		return t, fmt.Errorf("task not found: %s", taskID)
+	}
+	return t, json.Unmarshal(data, &t)
+}
+
+// putTaskTx saves the task, updating its last update time
+func putTaskTx(b *bbolt.Bucket, t types.Task) error {
+	t.LastUpdate = time.Now()
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := b.Put([]byte(t.ID), data); err != nil {
+		return err
+	}
+	return indexTaskTx(b.Tx(), activeDocKey(t.ID), t)
+}
+
+// SetTaskStatus changes the status of an active task. Setting the status to complete archives the task, the same as CompleteTask.
+func SetTaskStatus(id string, status int) error {
+	if status == constants.TaskStatus.Complete {
+		return CompleteTask(id)
+	}
+	task, err := GetTask(id)
+	if err != nil {
+		return err
+	}
+	task.Status = status
+	return UpdateTask(*task)
+}
+
 // GetTask retrieves a task by ID
 func GetTask(id string) (*types.Task, error) {
 	db := storage.DB()
@@ -265,8 +287,14 @@ func DeleteTask(id string) error {
 	}
 
 	return db.Update(func(tx *bbolt.Tx) error {
+		if err := clearTimerTx(tx, id); err != nil {
+			return err
+		}
 		b := tx.Bucket([]byte(storage.ACTIVE_BUCKET))
-		return b.Delete([]byte(id))
+		if err := b.Delete([]byte(id)); err != nil {
+			return err
+		}
+		return unindexTaskTx(tx, activeDocKey(id))
 	})
 }
 
@@ -281,189 +309,34 @@ func DeleteAllTasks() error {
 		if b == nil {
 			return nil
 		}
-		return b.ForEach(func(k, v []byte) error {
-			return b.Delete(k)
+		var ids []string
+		err := b.ForEach(func(k, v []byte) error {
+			ids = append(ids, string(k))
+			return nil
 		})
-	})
-}
-
-// DisplayTasks prints a list of tasks in a formatted table
-func PrintListOfTasks(tasks []types.Task) {
-	totalWidth, _, err := term.GetSize(os.Stdin.Fd())
-	if err != nil {
-		log.Println("failed to get terminal size:", err)
-		totalWidth = 80
-	}
-	totalWidth -= 2 // make space for the borders on the sides
-	titleWidth := totalWidth - baseTableWidth()
-	if titleWidth < colWidths[colTitle] {
-		// terminal is too small for the current configuration; consider removing columns
-		overflow := colWidths[colTitle] - titleWidth
-		removeHeader(colPriority) // hide priority col
-		if overflow >= 7 {
-			// also hide last update col as a final measure
-			removeHeader(colLastUpdate)
-		}
-		// recalculate title width after column removals
-		titleWidth = totalWidth - baseTableWidth()
-	}
-	colWidths[colTitle] = titleWidth
-
-	// Create top border, header separator, and bottom border with lighter color
-	borderColor := color.New(color.FgHiBlack) // Set up gray color for borders
-	topBorder := borderColor.Sprintf("┌%s┐\n", strings.Repeat("─", totalWidth))
-	headerSeparator := borderColor.Sprintf("├%s┤\n", strings.Repeat("─", totalWidth))
-	bottomBorder := borderColor.Sprintf("└%s┘\n", strings.Repeat("─", totalWidth))
-
-	// Print the top border
-	fmt.Print(topBorder)
-
-	// Print the headers without vertical separators
-	fmt.Print(borderColor.Sprintf("│"))
-	for i, header := range headers {
-		if header == "X" {
-			// ignore removed headers
-			continue
-		}
-		fmt.Printf(" %-*s", colWidths[header], header)
-		if i < len(headers)-1 {
-			fmt.Print("  ")
+		if err != nil {
+			return err
 		}
-	}
-	fmt.Print(borderColor.Sprintf(" │\n") + headerSeparator)
-
-	// Print each task row
-	for _, task := range tasks {
-		fmt.Print(borderColor.Sprintf("│"))
-		for i, header := range headers {
-			var value string
-			switch header {
-			case colID:
-				value = task.ID[:8]
-			case colTitle:
-				value = task.Title
-			case colCategory:
-				value = task.Category
-			case colDueDate:
-				value = formatDate(task.DueDate, task.Status == constants.TaskStatus.Complete)
-			case colStatus:
-				value = formatStatus(task.Status)
-			case colPriority:
-				value = fmt.Sprintf("%d", task.Priority)
-			case colLastUpdate:
-				value = timeSinceDateFormat(task.LastUpdate)
-			case "X":
-				continue // deleted header due to terminal being too small
-			default:
-				value = "?" // unknown header
+		// keys aren't deleted while iterating, since that makes the cursor skip some
+		for _, id := range ids {
+			if err := clearTimerTx(tx, id); err != nil {
+				return err
 			}
-			value = util.Truncate(value, colWidths[header])
-
-			// if colors were used, we may need to add extra padding due to invisible ansi stuff
-			value = addPadding(value, colWidths[header])
-			fmt.Printf(" %-*s", colWidths[header], value)
-			if i < len(headers)-1 {
-				fmt.Print("  ")
+			if err := b.Delete([]byte(id)); err != nil {
+				return err
+			}
+			if err := unindexTaskTx(tx, activeDocKey(id)); err != nil {
+				return err
 			}
 		}
-		fmt.Print(borderColor.Sprintf(" │\n"))
-	}
-
-	// Print the bottom border
-	fmt.Print(bottomBorder)
-}
-
-// sum calculates the total width of the fixed-size columns (i.e. those besides the title)
-// including padding
-func baseTableWidth() int {
-	total := 2 // left border of table
-	for i, header := range headers {
-		// exclude title from calc. also exclude any removed headers (if terminal is too small, some may be removed)
-		if header == colTitle || header == "X" {
-			continue
-		}
-		total += colWidths[header] + 1
-		if i < len(headers)-1 {
-			total += 2 // gap between each column
-		}
-	}
-	return total + 2 // right border of table
-}
-
-// formatDate formats the given date to M-D. If year is not current, also shows year at the end in parentheses.
-func formatDate(date time.Time, skipColor bool) string {
-	// Create a new time for the end of today
-	now := time.Now()
-	t := time.Date(
-		now.Year(), now.Month(), now.Day(),
-		23, 59, 59, 999999999, now.Location())
-
-	dateYesterday := t.AddDate(0, 0, -1)
-	dateTwoDaysAgo := t.AddDate(0, 0, -2)
-	dateTomorrow := t.AddDate(0, 0, 1)
-
-	out := date.Format("1-2")
-	if date.Year() != t.Year() {
-		out += "-" + date.Format("2006")
-	}
-
-	if !skipColor {
-		switch {
-		case date.Before(dateTwoDaysAgo):
-			out = veryLate.Sprint(out)
-		case date.Before(dateYesterday):
-			out = late.Sprint(out)
-		case date.Before(t):
-			out = today.Sprint(out)
-		case date.Before(dateTomorrow):
-			out = tomorrow.Sprint(out)
-		}
-	}
-
-	return out
-}
-
-// timeSinceDateFormat returns the number of days, weeks, or months since the given date.
-//
-// the string is formatted as a number followed by a letter which represents the unit ("d", "w", or "m").
-func timeSinceDateFormat(date time.Time) string {
-	duration := time.Since(date)
-
-	days := int(duration.Hours() / 24)
-	if days < 14 {
-		return fmt.Sprintf("%vd", days)
-	}
-	weeks := days / 7
-	if weeks < 8 {
-		return fmt.Sprintf("%vw", weeks)
-	}
-	months := days / 30
-	return fmt.Sprintf("%vm", months)
-}
-
-func formatStatus(status int) string {
-	out := constants.TaskStatusDisplay[status]
-	if status == constants.TaskStatus.Complete {
-		out = comp.Sprint(out)
-	} else if status == constants.TaskStatus.InProgress {
-		out = prog.Sprint(out)
-	}
-	return out
-}
-
-func addPadding(s string, colWidth int) string {
-	visibleLength := len(util.StripAnsi(s))
-	padding := colWidth - visibleLength
-	if padding > 0 {
-		s += strings.Repeat(" ", padding)
-	}
-	return s
+		return nil
+	})
 }
 
 // FindTasksByIDPrefix finds a list of potential ID matches for a given ID prefix string.
 func FindTasksByIDPrefix(prefix string) ([]string, error) {
 	var matchingIDs []string
-	if len(prefix) > 8 {
+	if len(prefix) > maxIDLen {
 		return matchingIDs, errors.New("given ID prefix is too long")
 	}
 
@@ -479,15 +352,7 @@ func FindTasksByIDPrefix(prefix string) ([]string, error) {
 		}
 
 		return b.ForEach(func(k, v []byte) error {
-			id := string(k)
-			if len(prefix) == len(id) {
-				if prefix == id {
-					matchingIDs = append(matchingIDs, id)
-				}
-				return nil
-			}
-
-			if id[:len(prefix)] == prefix {
+			if id := string(k); strings.HasPrefix(id, prefix) {
 				matchingIDs = append(matchingIDs, id)
 			}
 			return nil
@@ -495,11 +360,3 @@ func FindTasksByIDPrefix(prefix string) ([]string, error) {
 	})
 	return matchingIDs, err
 }
-
-func removeHeader(h string) {
-	for i := 0; i < len(headers); i++ {
-		if headers[i] == h {
-			headers[i] = "X"
-		}
-	}
-}
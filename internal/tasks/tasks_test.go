@@ -0,0 +1,40 @@
+package tasks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/webbben/task/internal/testutil"
+	"github.com/webbben/task/internal/types"
+)
+
+func TestDeleteAllTasksClearsTimer(t *testing.T) {
+	testutil.OpenTempDatabase(t)
+
+	task, err := CreateTask(types.Task{Title: "timed task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StartTimer(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteAllTasks(); err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := RunningTimer()
+	if err != nil || running != nil {
+		t.Errorf("RunningTimer() = %v, %v after deleting all tasks, want no timer", running, err)
+	}
+	if _, _, err := StopTimer(); !errors.Is(err, ErrNoTimer) {
+		t.Errorf("StopTimer() error = %v after deleting all tasks, want ErrNoTimer", err)
+	}
+	// a new task can be timed right away
+	next, err := CreateTask(types.Task{Title: "next task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped, err := StartTimer(next.ID); err != nil || stopped != nil {
+		t.Errorf("StartTimer() = %v, %v, want no stopped task", stopped, err)
+	}
+}
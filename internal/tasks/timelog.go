@@ -0,0 +1,189 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+	"go.etcd.io/bbolt"
+)
+
+// key in the meta bucket holding the ID of the task whose timer is running
+const timerKey = "timer"
+
+var ErrNoTimer = errors.New("no timer is running")
+
+// StartTimer starts timing work on a task. Only one timer runs at a time, so if another task's timer is running it's
+// stopped first, and that task is returned.
+func StartTimer(id string) (*types.Task, error) {
+	db := storage.DB()
+	if db == nil {
+		return nil, errors.New("failed to get task database")
+	}
+
+	var stopped *types.Task
+	err := db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(storage.ACTIVE_BUCKET))
+		meta := tx.Bucket([]byte(storage.META_BUCKET))
+		if b == nil || meta == nil {
+			return errors.New("failed to get task database")
+		}
+		task, err := getTaskTx(b, id)
+		if err != nil {
+			return err
+		}
+		if string(meta.Get([]byte(timerKey))) == id {
+			return fmt.Errorf("timer is already running for %s", id)
+		}
+		stopped, _, err = stopTimerTx(tx, time.Now())
+		if err != nil && !errors.Is(err, ErrNoTimer) {
+			return err
+		}
+
+		task.TimeLog = append(task.TimeLog, types.TimeEntry{Start: time.Now()})
+		if err := putTaskTx(b, task); err != nil {
+			return err
+		}
+		return meta.Put([]byte(timerKey), []byte(id))
+	})
+	return stopped, err
+}
+
+// StopTimer stops the running timer, returning the task it was running for and the time entry that was logged
+func StopTimer() (types.Task, types.TimeEntry, error) {
+	db := storage.DB()
+	if db == nil {
+		return types.Task{}, types.TimeEntry{}, errors.New("failed to get task database")
+	}
+
+	var task *types.Task
+	var entry types.TimeEntry
+	err := db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		task, entry, err = stopTimerTx(tx, time.Now())
+		return err
+	})
+	if err != nil {
+		return types.Task{}, entry, err
+	}
+	return *task, entry, nil
+}
+
+func stopTimerTx(tx *bbolt.Tx, now time.Time) (*types.Task, types.TimeEntry, error) {
+	meta := tx.Bucket([]byte(storage.META_BUCKET))
+	b := tx.Bucket([]byte(storage.ACTIVE_BUCKET))
+	if meta == nil || b == nil {
+		return nil, types.TimeEntry{}, ErrNoTimer
+	}
+	id := meta.Get([]byte(timerKey))
+	if id == nil {
+		return nil, types.TimeEntry{}, ErrNoTimer
+	}
+	if err := meta.Delete([]byte(timerKey)); err != nil {
+		return nil, types.TimeEntry{}, err
+	}
+	task, err := getTaskTx(b, string(id))
+	if err != nil {
+		// the task is gone, so there's nothing to log the time on
+		return nil, types.TimeEntry{}, ErrNoTimer
+	}
+	if !task.TimerRunning() {
+		return nil, types.TimeEntry{}, ErrNoTimer
+	}
+	last := &task.TimeLog[len(task.TimeLog)-1]
+	last.End = now
+	entry := *last
+	return &task, entry, putTaskTx(b, task)
+}
+
+// clearTimerTx forgets the running timer if it belongs to the given task, e.g. when the task is completed or deleted
+func clearTimerTx(tx *bbolt.Tx, id string) error {
+	meta := tx.Bucket([]byte(storage.META_BUCKET))
+	if meta == nil || string(meta.Get([]byte(timerKey))) != id {
+		return nil
+	}
+	return meta.Delete([]byte(timerKey))
+}
+
+// LogTime adds a finished time entry to a task, e.g. one tracked by "task focus" rather than a timer
+func LogTime(id string, entry types.TimeEntry) error {
+	if entry.Running() || !entry.End.After(entry.Start) {
+		return errors.New("time entry must have an end after its start")
+	}
+	return updateTask(id, func(t *types.Task) error {
+		t.TimeLog = append(t.TimeLog, entry)
+		// keep the entries in order, without moving a running entry from the end
+		end := len(t.TimeLog)
+		if t.TimerRunning() {
+			end--
+		}
+		sort.SliceStable(t.TimeLog[:end], func(i, j int) bool { return t.TimeLog[i].Start.Before(t.TimeLog[j].Start) })
+		return nil
+	})
+}
+
+// RunningTimer returns the task whose timer is running, or nil if there is none
+func RunningTimer() (*types.Task, error) {
+	db := storage.DB()
+	if db == nil {
+		return nil, errors.New("failed to get task database")
+	}
+
+	var task *types.Task
+	err := db.View(func(tx *bbolt.Tx) error {
+		meta := tx.Bucket([]byte(storage.META_BUCKET))
+		b := tx.Bucket([]byte(storage.ACTIVE_BUCKET))
+		// databases created before timers existed have no meta bucket until they're opened for writing
+		if meta == nil || b == nil {
+			return nil
+		}
+		id := meta.Get([]byte(timerKey))
+		if id == nil {
+			return nil
+		}
+		t, err := getTaskTx(b, string(id))
+		if err != nil {
+			return nil
+		}
+		task = &t
+		return nil
+	})
+	return task, err
+}
+
+// Timesheet totals the time logged on the given tasks between since and until, by day and then by category.
+// entries that span midnight are split between the days.
+func Timesheet(tasks []types.Task, since, until time.Time) map[time.Time]map[string]time.Duration {
+	sheet := make(map[time.Time]map[string]time.Duration)
+	for _, t := range tasks {
+		for _, e := range t.TimeLog {
+			start, end := e.Start, e.End
+			if e.Running() {
+				end = time.Now()
+			}
+			if start.Before(since) {
+				start = since
+			}
+			if end.After(until) {
+				end = until
+			}
+			for start.Before(end) {
+				day := util.RoundDateDown(start)
+				dayEnd := end
+				if next := day.AddDate(0, 0, 1); next.Before(end) {
+					dayEnd = next
+				}
+				if sheet[day] == nil {
+					sheet[day] = make(map[string]time.Duration)
+				}
+				sheet[day][t.Category] += dayEnd.Sub(start)
+				start = dayEnd
+			}
+		}
+	}
+	return sheet
+}
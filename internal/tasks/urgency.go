@@ -0,0 +1,117 @@
+package tasks
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/webbben/task/internal/config"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+const (
+	// overdue days and age stop adding urgency after this many days, so old tasks don't drown out everything else
+	maxUrgencyDays = 30
+)
+
+// UrgencyTerm is one part of a task's urgency score
+type UrgencyTerm struct {
+	Name   string
+	Detail string
+	Score  float64
+}
+
+// Urgency scores how urgent a task is, using the weights from the urgency config. The score is the sum of the terms:
+//
+//   - overdue: per day past the due date
+//   - due soon: for tasks due today, or half for tomorrow
+//   - priority: per priority level
+//   - age: per day since the task was last updated
+//   - in progress: for tasks that have been started
+//   - tags: per tag with a configured weight
+//   - blocked: for tasks with the blocked tag
+//
+// completed tasks always score 0.
+func Urgency(t types.Task, now time.Time) (float64, []UrgencyTerm) {
+	if t.Status == constants.TaskStatus.Complete {
+		return 0, nil
+	}
+	w := config.Get().Urgency
+	terms := make([]UrgencyTerm, 0)
+	add := func(name, detail string, score float64) {
+		if score != 0 {
+			terms = append(terms, UrgencyTerm{Name: name, Detail: detail, Score: score})
+		}
+	}
+
+	today := util.RoundDateDown(now)
+	daysUntilDue := int(math.Round(util.RoundDateDown(t.Due()).Sub(today).Hours() / 24))
+	switch {
+	case daysUntilDue < 0:
+		days := min(-daysUntilDue, maxUrgencyDays)
+		add("overdue", fmt.Sprintf("%d day(s) × %g", days, w.Overdue), float64(days)*w.Overdue)
+		// an overdue task is also at least as urgent as one due today
+		add("due soon", "overdue", w.DueSoon)
+	case daysUntilDue == 0:
+		add("due soon", "due today", w.DueSoon)
+	case daysUntilDue == 1:
+		add("due soon", "due tomorrow", w.DueSoon/2)
+	}
+
+	add("priority", fmt.Sprintf("%d × %g", t.Priority, w.Priority), float64(t.Priority)*w.Priority)
+
+	ageDays := min(now.Sub(t.LastUpdate).Hours()/24, maxUrgencyDays)
+	if ageDays >= 1 {
+		add("age", fmt.Sprintf("%.0f day(s) since update × %g", ageDays, w.Age), math.Floor(ageDays)*w.Age)
+	}
+
+	if t.Status == constants.TaskStatus.InProgress {
+		add("in progress", "", w.InProgress)
+	}
+	for _, tag := range t.Tags {
+		if tag == w.BlockedTag && w.BlockedTag != "" {
+			add("blocked", "tagged "+tag, w.Blocked)
+			continue
+		}
+		add("tag", tag, w.Tags[tag])
+	}
+
+	score := 0.0
+	for _, term := range terms {
+		score += term.Score
+	}
+	return score, terms
+}
+
+// UrgencyScore returns the urgency score of a task right now
+func UrgencyScore(t types.Task) float64 {
+	score, _ := Urgency(t, time.Now())
+	return score
+}
+
+// SortByUrgency sorts tasks from most to least urgent. Ties are broken by due date.
+func SortByUrgency(t []types.Task) {
+	now := time.Now()
+	type scored struct {
+		task  types.Task
+		score float64
+	}
+	// score each task once, rather than on every comparison
+	list := make([]scored, len(t))
+	for i, task := range t {
+		list[i].task = task
+		list[i].score, _ = Urgency(task, now)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		return list[i].task.Due().Before(list[j].task.Due())
+	})
+	for i := range list {
+		t[i] = list[i].task
+	}
+}
@@ -0,0 +1,91 @@
+package tasks
+
+import (
+	"sort"
+	"time"
+
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+// WeekVelocity is the work completed in a single week
+type WeekVelocity struct {
+	Start     time.Time
+	Completed int
+	Points    float64
+	// Estimated and Actual are the estimated and logged time of the completed tasks that had a time estimate
+	Estimated time.Duration
+	Actual    time.Duration
+}
+
+// CategoryAccuracy compares the estimated and logged time of the completed tasks in a category
+type CategoryAccuracy struct {
+	Category  string
+	Tasks     int
+	Estimated time.Duration
+	Actual    time.Duration
+}
+
+// Ratio is the logged time divided by the estimated time, so more than 1 means the category's tasks took longer than estimated
+func (c CategoryAccuracy) Ratio() float64 {
+	if c.Estimated == 0 {
+		return 0
+	}
+	return float64(c.Actual) / float64(c.Estimated)
+}
+
+// Velocity groups completed tasks by the week they were completed in (from since until now), and compares the
+// estimates with the time logged per category, worst underestimated first.
+// only tasks with a time estimate and some logged time count towards the estimate accuracy.
+func Velocity(completed []types.Task, since time.Time, weekStart time.Weekday) ([]WeekVelocity, []CategoryAccuracy) {
+	weeks := make([]WeekVelocity, 0)
+	for start := util.StartOfWeek(since, weekStart); !start.After(time.Now()); start = start.AddDate(0, 0, 7) {
+		weeks = append(weeks, WeekVelocity{Start: start})
+	}
+	byCategory := make(map[string]*CategoryAccuracy)
+
+	for _, t := range completed {
+		// completing a task is its last update
+		done := t.LastUpdate
+		if done.Before(since) {
+			continue
+		}
+		i := sort.Search(len(weeks), func(i int) bool { return weeks[i].Start.After(done) }) - 1
+		if i < 0 {
+			continue
+		}
+		w := &weeks[i]
+		w.Completed++
+		if t.Estimate == nil {
+			continue
+		}
+		w.Points += t.Estimate.Points
+
+		spent := t.TimeSpent()
+		if t.Estimate.Time == 0 || spent == 0 {
+			continue
+		}
+		w.Estimated += t.Estimate.Time
+		w.Actual += spent
+		c := byCategory[t.Category]
+		if c == nil {
+			c = &CategoryAccuracy{Category: t.Category}
+			byCategory[t.Category] = c
+		}
+		c.Tasks++
+		c.Estimated += t.Estimate.Time
+		c.Actual += spent
+	}
+
+	categories := make([]CategoryAccuracy, 0, len(byCategory))
+	for _, c := range byCategory {
+		categories = append(categories, *c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Ratio() != categories[j].Ratio() {
+			return categories[i].Ratio() > categories[j].Ratio()
+		}
+		return categories[i].Category < categories[j].Category
+	})
+	return weeks, categories
+}
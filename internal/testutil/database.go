@@ -0,0 +1,17 @@
+package testutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/webbben/task/internal/storage"
+)
+
+// OpenTempDatabase opens an empty task database in a temporary directory, and closes it when the test ends
+func OpenTempDatabase(t *testing.T) {
+	t.Helper()
+	if err := storage.OpenDatabaseFile(filepath.Join(t.TempDir(), storage.TASK_DB), storage.OpenOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(storage.CloseDatabase)
+}
@@ -0,0 +1,31 @@
+// Package testutil has helpers shared by the tests of other packages. It's only imported from _test.go files.
+package testutil
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// CheckGolden compares got with the golden file testdata/<name>.golden of the package being tested.
+// With -update, the golden file is rewritten with got instead, so changes can be reviewed in the diff.
+func CheckGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output doesn't match %s (run the tests with -update and review the diff):\n%s", path, got)
+	}
+}
@@ -1,18 +1,239 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// NoteTimeFormat is the time format notes are shown with. Notes used to be named by their time in this format.
+const NoteTimeFormat = "1-2-2006 15:04"
+
 type Task struct {
-	ID          string            `json:"id"`
-	Title       string            `json:"title"`
-	Description string            `json:"description"`
-	Category    string            `json:"category"`
-	DueDate     time.Time         `json:"due_date"`
-	Status      int               `json:"status"`
-	Priority    int               `json:"priority"`
-	ChildTasks  []Task            `json:"child_tasks"`
-	Notes       map[string]string `json:"notes"`
-	LastUpdate  time.Time         `json:"last_update"`
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Tags        []string  `json:"tags,omitempty"`
+	DueDate     time.Time `json:"due_date"`
+	// DueHasTime is set when the task is due at a time of day. Otherwise only the calendar date of DueDate is used; see Due.
+	DueHasTime bool `json:"due_has_time,omitempty"`
+	// WaitUntil hides the task from the task list until this time; see "task snooze"
+	WaitUntil  *time.Time `json:"wait_until,omitempty"`
+	Status     int        `json:"status"`
+	Priority   int        `json:"priority"`
+	Estimate   *Estimate  `json:"estimate,omitempty"`
+	ChildTasks []Task     `json:"child_tasks"`
+	Notes      Notes      `json:"notes"`
+	// Attachments are links and files attached to the task, in the order they were added
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// TimeLog is the time worked on the task. The last entry has no end time while its timer is running.
+	TimeLog    []TimeEntry `json:"time_log,omitempty"`
+	LastUpdate time.Time   `json:"last_update"`
+	// Created is when the task was added. It's zero for tasks added before it was recorded; see CreatedAt.
+	Created time.Time `json:"created"`
+
+	// Workspace is the workspace the task was loaded from. It's only set when listing tasks across workspaces.
+	Workspace string `json:"-"`
+}
+
+// Note is a single note on a task. The ID stays the same when the note is edited or moved to another task.
+type Note struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title,omitempty"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+	Edited  time.Time `json:"edited"`
+}
+
+// Estimate is how much work a task is expected to take, either as time or as story points
+type Estimate struct {
+	Time   time.Duration `json:"time,omitempty"`
+	Points float64       `json:"points,omitempty"`
+}
+
+// String formats the estimate the same way it's entered, e.g. "1h30m" or "3pt"
+func (e Estimate) String() string {
+	if e.Points != 0 {
+		return strconv.FormatFloat(e.Points, 'f', -1, 64) + "pt"
+	}
+	s := e.Time.String()
+	// time.Duration adds zero units, e.g. 2h0m0s. only whole zero units are dropped, so 2m30s stays as it is.
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// TimeEntry is a period of time worked on a task
+type TimeEntry struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Running returns true if the entry's timer hasn't been stopped yet
+func (e TimeEntry) Running() bool {
+	return e.End.IsZero()
+}
+
+// Duration returns the length of the entry, counting up to now if it's still running
+func (e TimeEntry) Duration() time.Duration {
+	if e.Running() {
+		return time.Since(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+// TimeSpent returns the total time logged on the task, including a running timer
+func (t Task) TimeSpent() time.Duration {
+	var total time.Duration
+	for _, e := range t.TimeLog {
+		total += e.Duration()
+	}
+	return total
+}
+
+// Due returns when the task is due, in local time. For date-only due dates, it's the start of the due day.
+func (t Task) Due() time.Time {
+	if t.DueHasTime {
+		return t.DueDate.Local()
+	}
+	// the calendar date is read in the location it was stored with, so old due dates stored with a time of day or in UTC keep their date
+	d := t.DueDate
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// SetDue sets when the task is due. Without a time of day only the calendar date of due is kept,
+// stored as midnight UTC so that it's the same date in every timezone.
+func (t *Task) SetDue(due time.Time, hasTime bool) {
+	t.DueHasTime = hasTime
+	if hasTime {
+		t.DueDate = due
+		return
+	}
+	t.DueDate = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DueString formats the due date with the given date layout, adding the time of day if one is set
+func (t Task) DueString(layout string) string {
+	if t.DueHasTime {
+		return t.Due().Format(layout + " 15:04")
+	}
+	return t.Due().Format(layout)
+}
+
+// CreatedAt returns when the task was added. For tasks added before that was recorded, it's the earliest time
+// recorded on the task: its first note or time entry, or else its last update.
+func (t Task) CreatedAt() time.Time {
+	if !t.Created.IsZero() {
+		return t.Created
+	}
+	earliest := t.LastUpdate
+	for _, n := range t.Notes {
+		if !n.Created.IsZero() && n.Created.Before(earliest) {
+			earliest = n.Created
+		}
+	}
+	for _, e := range t.TimeLog {
+		if e.Start.Before(earliest) {
+			earliest = e.Start
+		}
+	}
+	return earliest
+}
+
+// Waiting returns true if the task is snoozed until after now
+func (t Task) Waiting(now time.Time) bool {
+	return t.WaitUntil != nil && now.Before(*t.WaitUntil)
+}
+
+// WokeOn returns true if the task was snoozed until earlier on the same day as now
+func (t Task) WokeOn(now time.Time) bool {
+	if t.WaitUntil == nil || t.Waiting(now) {
+		return false
+	}
+	y1, m1, d1 := t.WaitUntil.Local().Date()
+	y2, m2, d2 := now.Local().Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// TimerRunning returns true if the task's timer is running
+func (t Task) TimerRunning() bool {
+	return len(t.TimeLog) > 0 && t.TimeLog[len(t.TimeLog)-1].Running()
+}
+
+// Attachment is a URL or a file attached to a task.
+// files are copied into the attachment store (see storage.StoreBlob) and referenced by the hash of their content.
+type Attachment struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+	// FileName is the original name of a stored file, which is kept so it opens with the right application
+	FileName string    `json:"file_name,omitempty"`
+	Hash     string    `json:"hash,omitempty"`
+	Size     int64     `json:"size,omitempty"`
+	Added    time.Time `json:"added"`
+}
+
+// IsURL returns true if the attachment is a link rather than a stored file
+func (a Attachment) IsURL() bool {
+	return a.URL != ""
+}
+
+// Name returns the note's title, or the time it was created if it has no title
+func (n Note) Name() string {
+	if n.Title != "" {
+		return n.Title
+	}
+	return n.Created.Format(NoteTimeFormat)
+}
+
+// Notes are a task's notes, oldest first
+type Notes []Note
+
+// UnmarshalJSON reads a list of notes, or the old format where notes were a map of name to content.
+// old notes were named by the time they were written, so that becomes the created time.
+func (n *Notes) UnmarshalJSON(data []byte) error {
+	var list []Note
+	if err := json.Unmarshal(data, &list); err == nil {
+		*n = list
+		return nil
+	}
+
+	var old map[string]string
+	if err := json.Unmarshal(data, &old); err != nil {
+		return fmt.Errorf("invalid notes: %w", err)
+	}
+	list = make([]Note, 0, len(old))
+	for name, content := range old {
+		note := Note{ID: legacyNoteID(name), Content: content}
+		if created, err := time.ParseInLocation(NoteTimeFormat, name, time.Local); err == nil {
+			note.Created = created
+			note.Edited = created
+		} else {
+			note.Title = name
+		}
+		list = append(list, note)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Created.Equal(list[j].Created) {
+			return list[i].Created.Before(list[j].Created)
+		}
+		return list[i].Title < list[j].Title
+	})
+	*n = list
+	return nil
+}
+
+// legacyNoteID derives the ID of a note from its old name, so it's the same every time the task is read until it's saved again
+func legacyNoteID(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%08x", h.Sum32())
 }
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateString(t *testing.T) {
+	tests := []struct {
+		estimate Estimate
+		want     string
+	}{
+		{Estimate{Time: 2 * time.Hour}, "2h"},
+		{Estimate{Time: 90 * time.Minute}, "1h30m"},
+		{Estimate{Time: 45 * time.Minute}, "45m"},
+		{Estimate{Time: 2*time.Minute + 30*time.Second}, "2m30s"},
+		{Estimate{Time: 10 * time.Second}, "10s"},
+		{Estimate{Time: time.Hour + 20*time.Second}, "1h0m20s"},
+		{Estimate{Points: 3}, "3pt"},
+		{Estimate{Points: 0.5}, "0.5pt"},
+	}
+	for _, tt := range tests {
+		if got := tt.estimate.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.estimate, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package board
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+const (
+	// how often to check if the database was changed by another process
+	refreshInterval = 2 * time.Second
+	// how long to wait for the database lock when reading or writing
+	lockTimeout = 2 * time.Second
+	// each card takes up this many lines, including its border
+	cardHeight = 4
+)
+
+var (
+	columnStyle = lipgloss.NewStyle().Padding(0, 1)
+	headerStyle = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+	cardStyle   = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("8")).
+			Padding(0, 1)
+	selectedCardStyle = cardStyle.BorderForeground(lipgloss.Color("12"))
+	dimStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	statusStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Padding(0, 1)
+	helpStyle         = dimStyle.Padding(0, 1)
+)
+
+type refreshTickMsg time.Time
+
+// column is a single status column of the board
+type column struct {
+	status int
+	cards  []types.Task
+}
+
+type model struct {
+	workspace string
+	dbModTime time.Time
+
+	columns []column
+	col     int // selected column
+	row     int // selected card in the selected column
+
+	status        string
+	width, height int
+}
+
+// Run launches the kanban board TUI for the given workspace
+func Run(workspace string) error {
+	m := &model{workspace: workspace}
+	if err := m.reload(); err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("error occurred while running board: %w", err)
+	}
+	return nil
+}
+
+func (m *model) Init() tea.Cmd {
+	return refreshTick()
+}
+
+func refreshTick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return refreshTickMsg(t)
+	})
+}
+
+// reload loads the tasks and sorts them into a column per status.
+// the pending, in progress and complete columns are always shown, plus a column for any other status in use.
+func (m *model) reload() error {
+	var active, completed []types.Task
+	err := storage.WithWorkspace(m.workspace, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}, func() error {
+		var err error
+		active, err = tasks.GetAllTasks()
+		if err != nil {
+			return err
+		}
+		// only today's completions, so the complete column doesn't grow forever
+		completed, err = tasks.GetCompletedTasks(util.RoundDateDown(time.Now()))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(storage.WorkspacePath(m.workspace)); err == nil {
+		m.dbModTime = info.ModTime()
+	}
+
+	byStatus := map[int][]types.Task{
+		constants.TaskStatus.Pending:    {},
+		constants.TaskStatus.InProgress: {},
+		constants.TaskStatus.Complete:   {},
+	}
+	for _, t := range append(active, completed...) {
+		byStatus[t.Status] = append(byStatus[t.Status], t)
+	}
+
+	selectedID := ""
+	if t := m.selectedTask(); t != nil {
+		selectedID = t.ID
+	}
+
+	m.columns = make([]column, 0, len(byStatus))
+	for status, cards := range byStatus {
+		sort.SliceStable(cards, func(i, j int) bool {
+			if cards[i].Due().Equal(cards[j].Due()) {
+				return cards[i].Priority > cards[j].Priority
+			}
+			return cards[i].Due().Before(cards[j].Due())
+		})
+		m.columns = append(m.columns, column{status: status, cards: cards})
+	}
+	// statuses are ordered by their value, which follows the flow of work (complete is last)
+	sort.Slice(m.columns, func(i, j int) bool { return m.columns[i].status < m.columns[j].status })
+
+	m.selectTask(selectedID)
+	return nil
+}
+
+// selectTask moves the selection to the task with the given ID, or keeps it within bounds if it's gone
+func (m *model) selectTask(id string) {
+	for c, col := range m.columns {
+		for r, t := range col.cards {
+			if t.ID == id {
+				m.col, m.row = c, r
+				return
+			}
+		}
+	}
+	m.clampSelection()
+}
+
+func (m *model) clampSelection() {
+	m.col = max(0, min(m.col, len(m.columns)-1))
+	if len(m.columns) == 0 {
+		m.row = 0
+		return
+	}
+	m.row = max(0, min(m.row, len(m.columns[m.col].cards)-1))
+}
+
+func (m *model) selectedTask() *types.Task {
+	if m.col >= len(m.columns) || m.row >= len(m.columns[m.col].cards) {
+		return nil
+	}
+	return &m.columns[m.col].cards[m.row]
+}
+
+// moveCard moves the selected card to the column in the given direction, saving its new status
+func (m *model) moveCard(dir int) {
+	t := m.selectedTask()
+	if t == nil {
+		return
+	}
+	target := m.col + dir
+	if target < 0 || target >= len(m.columns) {
+		return
+	}
+	if t.Status == constants.TaskStatus.Complete {
+		m.status = "Completed tasks are archived and can't be moved."
+		return
+	}
+	id, title, status := t.ID, t.Title, m.columns[target].status
+	err := storage.WithWorkspace(m.workspace, storage.OpenOptions{Timeout: lockTimeout}, func() error {
+		return tasks.SetTaskStatus(id, status)
+	})
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	// completed tasks get a new ID when archived, so selectTask won't find them; leave the selection in the target column
+	m.col = target
+	if err := m.reload(); err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.selectTask(id)
+	m.status = fmt.Sprintf("Moved %q to %s", title, statusName(status))
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case refreshTickMsg:
+		if info, err := os.Stat(storage.WorkspacePath(m.workspace)); err == nil && !info.ModTime().Equal(m.dbModTime) {
+			if err := m.reload(); err != nil {
+				m.status = "Error refreshing: " + err.Error()
+			}
+		}
+		return m, refreshTick()
+	case tea.KeyMsg:
+		m.status = ""
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "left", "h":
+			m.col--
+			m.clampSelection()
+		case "right", "l":
+			m.col++
+			m.clampSelection()
+		case "up", "k":
+			m.row--
+			m.clampSelection()
+		case "down", "j":
+			m.row++
+			m.clampSelection()
+		case "shift+left", "H", "<":
+			m.moveCard(-1)
+		case "shift+right", "L", ">":
+			m.moveCard(1)
+		case "r":
+			if err := m.reload(); err != nil {
+				m.status = "Error: " + err.Error()
+			}
+		}
+	}
+	return m, nil
+}
+
+func statusName(status int) string {
+	if name, ok := constants.TaskStatusDisplay[status]; ok {
+		return name
+	}
+	return fmt.Sprintf("status %d", status)
+}
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return "\n Initializing..."
+	}
+	colWidth := m.width / max(1, len(m.columns))
+	// room for the column header, the status line and the help line
+	visibleCards := max(1, (m.height-4)/cardHeight)
+
+	cols := make([]string, len(m.columns))
+	for c, col := range m.columns {
+		cardWidth := colWidth - columnStyle.GetHorizontalFrameSize() - cardStyle.GetHorizontalFrameSize()
+		header := headerStyle.Render(fmt.Sprintf("%s (%d)", strings.ToUpper(statusName(col.status)), len(col.cards)))
+
+		// scroll the column so the selected card is visible
+		start := 0
+		if c == m.col && m.row >= visibleCards {
+			start = m.row - visibleCards + 1
+		}
+		end := min(len(col.cards), start+visibleCards)
+
+		cards := []string{header}
+		for r := start; r < end; r++ {
+			style := cardStyle
+			if c == m.col && r == m.row {
+				style = selectedCardStyle
+			}
+			// lipgloss widths include the padding, but not the border
+			cards = append(cards, style.Width(cardWidth+style.GetHorizontalPadding()).Render(m.cardView(col.cards[r], cardWidth)))
+		}
+		if end < len(col.cards) {
+			cards = append(cards, dimStyle.Render(fmt.Sprintf("  +%d more", len(col.cards)-end)))
+		}
+		cols[c] = columnStyle.Width(colWidth).Render(strings.Join(cards, "\n"))
+	}
+
+	board := lipgloss.JoinHorizontal(lipgloss.Top, cols...)
+	board = lipgloss.NewStyle().Height(m.height - 2).MaxHeight(m.height - 2).Render(board)
+	help := helpStyle.Render("←/→ h/l: column • ↑/↓ j/k: card • shift+←/→ H/L: move card • r: refresh • q: quit")
+	return lipgloss.JoinVertical(lipgloss.Left, board, statusStyle.Render(m.status), help)
+}
+
+func (m *model) cardView(t types.Task, width int) string {
+	title := util.Truncate(t.Title, width)
+	details := fmt.Sprintf("%s · due %s", util.Cut(t.ID, 8), t.DueString("Jan 2"))
+	if t.Priority != 0 {
+		details += fmt.Sprintf(" · p%d", t.Priority)
+	}
+	return title + "\n" + dimStyle.Render(util.Truncate(details, width))
+}
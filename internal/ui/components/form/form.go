@@ -0,0 +1,121 @@
+package form
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+	labelStyle = lipgloss.NewStyle().Width(14).Foreground(lipgloss.Color("8"))
+	focusStyle = labelStyle.Foreground(lipgloss.Color("12"))
+	helpStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).MarginTop(1)
+)
+
+// Field is a single line text field in the form
+type Field struct {
+	Label       string
+	Value       string
+	Placeholder string
+}
+
+// FormModel is a simple form made of single line text inputs.
+// tab / shift+tab move between fields, enter moves to the next field or submits on the last one, and esc cancels.
+type FormModel struct {
+	title    string
+	labels   []string
+	inputs   []textinput.Model
+	focus    int
+	width    int
+	onSubmit func(values []string)
+	onCancel func()
+}
+
+func New(title string, fields []Field, onSubmit func(values []string), onCancel func()) *FormModel {
+	m := &FormModel{
+		title:    title,
+		onSubmit: onSubmit,
+		onCancel: onCancel,
+	}
+	for _, f := range fields {
+		input := textinput.New()
+		input.SetValue(f.Value)
+		input.Placeholder = f.Placeholder
+		input.Prompt = ""
+		m.labels = append(m.labels, f.Label)
+		m.inputs = append(m.inputs, input)
+	}
+	if len(m.inputs) > 0 {
+		m.inputs[0].Focus()
+	}
+	return m
+}
+
+func (m FormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// SetWidth sets the width available to the form
+func (m *FormModel) SetWidth(width int) {
+	m.width = width
+	for i := range m.inputs {
+		m.inputs[i].Width = max(10, width-lipgloss.Width(labelStyle.Render(""))-2)
+	}
+}
+
+func (m *FormModel) setFocus(i int) tea.Cmd {
+	m.inputs[m.focus].Blur()
+	m.focus = (i + len(m.inputs)) % len(m.inputs)
+	return m.inputs[m.focus].Focus()
+}
+
+func (m *FormModel) values() []string {
+	values := make([]string, len(m.inputs))
+	for i, input := range m.inputs {
+		values[i] = strings.TrimSpace(input.Value())
+	}
+	return values
+}
+
+func (m *FormModel) Update(msg tea.Msg) (*FormModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			m.onCancel()
+			return m, nil
+		case "ctrl+s":
+			m.onSubmit(m.values())
+			return m, nil
+		case "enter":
+			if m.focus == len(m.inputs)-1 {
+				m.onSubmit(m.values())
+				return m, nil
+			}
+			return m, m.setFocus(m.focus + 1)
+		case "tab", "down":
+			return m, m.setFocus(m.focus + 1)
+		case "shift+tab", "up":
+			return m, m.setFocus(m.focus - 1)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+func (m FormModel) View() string {
+	lines := []string{titleStyle.Render(m.title)}
+	for i, input := range m.inputs {
+		style := labelStyle
+		if i == m.focus {
+			style = focusStyle
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, style.Render(m.labels[i]), input.View()))
+	}
+	lines = append(lines, helpStyle.Render("tab: next field • ctrl+s: save • esc: cancel"))
+	return strings.Join(lines, "\n")
+}
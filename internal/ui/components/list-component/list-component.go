@@ -3,6 +3,7 @@ package listcomponent
 import (
 	"log"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -32,7 +33,8 @@ func (m ListComponentModel) onEnter() {
 func (m ListComponentModel) Update(msg tea.Msg) (ListComponentModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "enter" {
+		// while filtering, enter applies the filter instead of selecting an item
+		if msg.String() == "enter" && !m.Filtering() {
 			m.onEnter()
 		}
 	case tea.WindowSizeMsg:
@@ -46,6 +48,48 @@ func (m ListComponentModel) Update(msg tea.Msg) (ListComponentModel, tea.Cmd) {
 	return m, cmd
 }
 
+// SetSize sets the size of the whole component, including its margins
+func (m *ListComponentModel) SetSize(width, height int) {
+	h, v := docStyle.GetFrameSize()
+	m.list.SetSize(width-h, height-v)
+}
+
+// SetItems replaces the items in the list
+func (m *ListComponentModel) SetItems(items []list.Item) tea.Cmd {
+	return m.list.SetItems(items)
+}
+
+// SelectedItem returns the currently selected item, or nil if there are no items
+func (m ListComponentModel) SelectedItem() list.Item {
+	return m.list.SelectedItem()
+}
+
+// Select selects the item at the given index
+func (m *ListComponentModel) Select(index int) {
+	m.list.Select(index)
+}
+
+// Filtering returns true while the user is typing a filter, so keys should go to the list instead of being handled as actions
+func (m ListComponentModel) Filtering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+// SetTitle sets the title shown above the list
+func (m *ListComponentModel) SetTitle(title string) {
+	m.list.Title = title
+}
+
+// SetStatusBarItemName sets the name used for the items in the status bar, e.g. "3 tasks"
+func (m *ListComponentModel) SetStatusBarItemName(singular, plural string) {
+	m.list.SetStatusBarItemName(singular, plural)
+}
+
+// SetAdditionalHelpKeys adds key bindings to the list's help view
+func (m *ListComponentModel) SetAdditionalHelpKeys(keys func() []key.Binding) {
+	m.list.AdditionalShortHelpKeys = keys
+	m.list.AdditionalFullHelpKeys = keys
+}
+
 func (m ListComponentModel) View() string {
 	return docStyle.Render(m.list.View())
 }
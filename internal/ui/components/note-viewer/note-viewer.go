@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -31,9 +32,12 @@ var (
 	}()
 )
 
+// NoteViewerModel shows a note in a scrollable viewport. Notes are rendered as Markdown by default,
+// and "m" toggles between the rendered and raw text.
 type NoteViewerModel struct {
 	textContent string
 	title       string
+	raw         bool
 	ready       bool
 	viewport    viewport.Model
 	onClose     func()
@@ -50,7 +54,38 @@ func New(title, textContent string, onClose func()) *NoteViewerModel {
 func (m *NoteViewerModel) SetNoteContent(title string, text string) {
 	m.title = title
 	m.textContent = text
-	m.viewport.SetContent(m.textContent)
+	m.viewport.SetContent(m.renderContent())
+	m.viewport.GotoTop()
+}
+
+// renderContent renders the note to fit the viewport width, as Markdown unless the raw view is on.
+// if the Markdown can't be rendered, the raw text is shown instead.
+func (m NoteViewerModel) renderContent() string {
+	width := max(10, m.viewport.Width)
+	if !m.raw {
+		if out, err := renderMarkdown(m.textContent, width); err == nil {
+			return out
+		}
+	}
+	return lipgloss.NewStyle().Width(width).Render(m.textContent)
+}
+
+func renderMarkdown(text string, width int) (string, error) {
+	// the style is picked from the background color lipgloss already detected, since glamour's auto style
+	// would query the terminal again while bubbletea is reading from it
+	style := "light"
+	if lipgloss.HasDarkBackground() {
+		style = "dark"
+	}
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		// glamour adds a margin of 2 on each side
+		glamour.WithWordWrap(width-4),
+	)
+	if err != nil {
+		return "", err
+	}
+	return r.Render(text)
 }
 
 func (m NoteViewerModel) Init() tea.Cmd {
@@ -63,8 +98,12 @@ func (m *NoteViewerModel) Update(msg tea.Msg) (*NoteViewerModel, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "q" {
+		switch msg.String() {
+		case "q":
 			m.onClose()
+		case "m":
+			m.raw = !m.raw
+			m.viewport.SetContent(m.renderContent())
 		}
 	case tea.WindowSizeMsg:
 		headerHeight := lipgloss.Height(m.headerView())
@@ -80,7 +119,7 @@ func (m *NoteViewerModel) Update(msg tea.Msg) (*NoteViewerModel, tea.Cmd) {
 			m.viewport = viewport.New(msg.Width, msg.Height-verticalMarginHeight)
 			m.viewport.YPosition = headerHeight
 			m.viewport.HighPerformanceRendering = useHighPerformanceRenderer
-			m.viewport.SetContent(m.textContent)
+			m.viewport.SetContent(m.renderContent())
 			m.ready = true
 
 			// This is only necessary for high performance rendering, which in
@@ -91,6 +130,8 @@ func (m *NoteViewerModel) Update(msg tea.Msg) (*NoteViewerModel, tea.Cmd) {
 		} else {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - verticalMarginHeight
+			// re-wrap the note for the new width
+			m.viewport.SetContent(m.renderContent())
 		}
 
 		if useHighPerformanceRenderer {
@@ -124,7 +165,11 @@ func (m NoteViewerModel) headerView() string {
 func (m NoteViewerModel) footerView() string {
 	info := infoStyle.Render(fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100))
 	line := strings.Repeat("─", max(0, m.viewport.Width-lipgloss.Width(info)))
-	nav := "ESC: exit"
+	view := "raw"
+	if m.raw {
+		view = "rendered"
+	}
+	nav := fmt.Sprintf("m: %s view • ESC: exit", view)
 	footerLine := lipgloss.JoinHorizontal(lipgloss.Center, line, info)
 	return lipgloss.JoinVertical(lipgloss.Left, footerLine, nav)
 }
@@ -0,0 +1,673 @@
+package dashboard
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/dates"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/ui/components/form"
+	listcomponent "github.com/webbben/task/internal/ui/components/list-component"
+	noteviewer "github.com/webbben/task/internal/ui/components/note-viewer"
+	"github.com/webbben/task/internal/util"
+)
+
+const (
+	// how often to check if the database was changed by another process
+	refreshInterval = 2 * time.Second
+	// how long to wait for the database lock when reading or writing
+	lockTimeout = 2 * time.Second
+	// format used for due dates in the edit form, which dates.ParseDue understands (with the time of day added if set)
+	formDateFormat = "1/2/2006"
+)
+
+var (
+	detailStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("8")).
+			Padding(0, 1).
+			MarginTop(1)
+	labelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Width(10)
+	titleStyle  = lipgloss.NewStyle().Bold(true)
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Padding(0, 2)
+)
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeForm
+	modeNotes
+	modeConfirm
+)
+
+type sortMode int
+
+const (
+	sortDue sortMode = iota
+	sortPriority
+	sortTitle
+	sortUpdated
+	sortModeCount
+)
+
+var sortModeNames = map[sortMode]string{
+	sortDue:      "due date",
+	sortPriority: "priority",
+	sortTitle:    "title",
+	sortUpdated:  "last update",
+}
+
+var keys = []key.Binding{
+	key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add")),
+	key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+	key.NewBinding(key.WithKeys("n"), key.WithHelp("n/N", "note/editor note")),
+	key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "complete")),
+	key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+	key.NewBinding(key.WithKeys("+"), key.WithHelp("+/-", "priority")),
+	key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort")),
+	key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "notes")),
+	key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+}
+
+type taskItem struct {
+	task types.Task
+}
+
+func (item taskItem) FilterValue() string {
+	return strings.Join(append([]string{item.task.Title, item.task.Category}, item.task.Tags...), " ")
+}
+
+func (item taskItem) Title() string {
+	if item.task.Status == constants.TaskStatus.Complete {
+		return "✓ " + item.task.Title
+	}
+	return item.task.Title
+}
+
+func (item taskItem) Description() string {
+	return fmt.Sprintf("%s · due %s · %s", util.Cut(item.task.ID, 8), item.task.DueString("Jan 2"), constants.TaskStatusDisplay[item.task.Status])
+}
+
+type refreshTickMsg time.Time
+
+type editorFinishedMsg struct {
+	taskID string
+	path   string
+	err    error
+}
+
+type model struct {
+	workspace string
+	tasks     []types.Task
+	dbModTime time.Time
+
+	list       listcomponent.ListComponentModel
+	noteViewer *noteviewer.NoteViewerModel
+	form       *form.FormModel
+	mode       mode
+	sort       sortMode
+
+	confirmPrompt string
+	onConfirm     func() error
+
+	status        string
+	width, height int
+}
+
+// Run launches the dashboard TUI for the given workspace
+func Run(workspace string) error {
+	m := &model{workspace: workspace}
+	m.list = listcomponent.New([]list.Item{}, "", 0, 0, m.onSelectTask)
+	m.list.SetStatusBarItemName("task", "tasks")
+	m.list.SetAdditionalHelpKeys(func() []key.Binding { return keys })
+	m.noteViewer = noteviewer.New("", "", m.closeNotes)
+
+	if err := m.reload(); err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("error occurred while running dashboard: %w", err)
+	}
+	return nil
+}
+
+func (m *model) Init() tea.Cmd {
+	return refreshTick()
+}
+
+func refreshTick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return refreshTickMsg(t)
+	})
+}
+
+// read opens the database read-only for the duration of fn, so other task processes aren't locked out in between
+func (m *model) read(fn func() error) error {
+	return storage.WithWorkspace(m.workspace, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}, fn)
+}
+
+// write opens the database for writing for the duration of fn, and reloads the tasks afterwards
+func (m *model) write(fn func() error) error {
+	err := storage.WithWorkspace(m.workspace, storage.OpenOptions{Timeout: lockTimeout}, fn)
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+func (m *model) dbChanged() bool {
+	info, err := os.Stat(storage.WorkspacePath(m.workspace))
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Equal(m.dbModTime)
+}
+
+// reload loads the active tasks and the tasks completed today, keeping the same task selected if it still exists
+func (m *model) reload() error {
+	var active, completed []types.Task
+	err := m.read(func() error {
+		var err error
+		active, err = tasks.GetAllTasks()
+		if err != nil {
+			return err
+		}
+		completed, err = tasks.GetCompletedTasks(util.RoundDateDown(time.Now()))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(storage.WorkspacePath(m.workspace)); err == nil {
+		m.dbModTime = info.ModTime()
+	}
+
+	m.tasks = append(active, completed...)
+	m.sortTasks()
+	m.updateList()
+	return nil
+}
+
+func (m *model) sortTasks() {
+	sort.SliceStable(m.tasks, func(i, j int) bool {
+		a, b := m.tasks[i], m.tasks[j]
+		// completed tasks always go at the bottom
+		aComp, bComp := a.Status == constants.TaskStatus.Complete, b.Status == constants.TaskStatus.Complete
+		if aComp != bComp {
+			return bComp
+		}
+		switch m.sort {
+		case sortPriority:
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+		case sortTitle:
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		case sortUpdated:
+			return a.LastUpdate.After(b.LastUpdate)
+		}
+		return a.Due().Before(b.Due())
+	})
+}
+
+func (m *model) updateList() {
+	selectedID := ""
+	if t := m.selectedTask(); t != nil {
+		selectedID = t.ID
+	}
+	items := make([]list.Item, len(m.tasks))
+	selected := 0
+	for i, t := range m.tasks {
+		items[i] = taskItem{task: t}
+		if t.ID == selectedID {
+			selected = i
+		}
+	}
+	m.list.SetItems(items)
+	m.list.Select(selected)
+	m.list.SetTitle(fmt.Sprintf("%s · sorted by %s", m.workspace, sortModeNames[m.sort]))
+}
+
+func (m *model) selectedTask() *types.Task {
+	item, ok := m.list.SelectedItem().(taskItem)
+	if !ok {
+		return nil
+	}
+	return &item.task
+}
+
+// selectedActiveTask returns the selected task, or sets an error status if it's already complete
+func (m *model) selectedActiveTask() *types.Task {
+	t := m.selectedTask()
+	if t == nil {
+		return nil
+	}
+	if t.Status == constants.TaskStatus.Complete {
+		m.status = "task is already complete"
+		return nil
+	}
+	return t
+}
+
+func (m *model) setResult(err error, success string) {
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.status = success
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.list.SetSize(m.listWidth(), m.height-2)
+		if m.form != nil {
+			m.form.SetWidth(m.detailWidth())
+		}
+		// the note viewer always uses the full window
+		var cmd tea.Cmd
+		m.noteViewer, cmd = m.noteViewer.Update(msg)
+		return m, cmd
+	case refreshTickMsg:
+		if m.mode == modeList && m.dbChanged() {
+			if err := m.reload(); err != nil {
+				m.status = "Error refreshing: " + err.Error()
+			}
+		}
+		return m, refreshTick()
+	case editorFinishedMsg:
+		m.finishEditorNote(msg)
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+	}
+
+	switch m.mode {
+	case modeNotes:
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+			m.closeNotes()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.noteViewer, cmd = m.noteViewer.Update(msg)
+		return m, cmd
+	case modeForm:
+		var cmd tea.Cmd
+		m.form, cmd = m.form.Update(msg)
+		return m, cmd
+	case modeConfirm:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "y", "Y":
+				m.setResult(m.onConfirm(), "Done.")
+			default:
+				m.status = "Cancelled."
+			}
+			m.mode = modeList
+		}
+		return m, nil
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && !m.list.Filtering() {
+		if handled, cmd := m.handleKey(msg); handled {
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// handleKey handles the dashboard actions. returns false if the key should be passed on to the list.
+func (m *model) handleKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	m.status = ""
+	switch msg.String() {
+	case "q":
+		return true, tea.Quit
+	case "a":
+		return true, m.openAddForm()
+	case "e":
+		return true, m.openEditForm()
+	case "n":
+		return true, m.openNoteForm()
+	case "N":
+		return true, m.openEditorNote()
+	case "c":
+		if t := m.selectedActiveTask(); t != nil {
+			id, title := t.ID, t.Title
+			m.setResult(m.write(func() error { return tasks.CompleteTask(id) }), "Completed: "+title)
+		}
+		return true, nil
+	case "d":
+		if t := m.selectedActiveTask(); t != nil {
+			id := t.ID
+			m.confirm(fmt.Sprintf("Delete %q? (y/n)", t.Title), func() error {
+				return m.write(func() error { return tasks.DeleteTask(id) })
+			})
+		}
+		return true, nil
+	case "+", "-":
+		if t := m.selectedActiveTask(); t != nil {
+			updated := *t
+			if msg.String() == "+" {
+				updated.Priority++
+			} else {
+				updated.Priority--
+			}
+			m.setResult(m.write(func() error { return tasks.UpdateTask(updated) }), fmt.Sprintf("Priority set to %d", updated.Priority))
+		}
+		return true, nil
+	case "s":
+		m.sort = (m.sort + 1) % sortModeCount
+		m.sortTasks()
+		m.updateList()
+		return true, nil
+	case "r":
+		m.setResult(m.reload(), "Refreshed.")
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *model) confirm(prompt string, onConfirm func() error) {
+	m.mode = modeConfirm
+	m.confirmPrompt = prompt
+	m.onConfirm = onConfirm
+}
+
+func (m *model) openForm(title string, fields []form.Field, onSubmit func(values []string) error) tea.Cmd {
+	m.form = form.New(title, fields, func(values []string) {
+		if err := onSubmit(values); err != nil {
+			// keep the form open so the input isn't lost
+			m.status = "Error: " + err.Error()
+			return
+		}
+		m.closeForm()
+	}, m.closeForm)
+	m.form.SetWidth(m.detailWidth())
+	m.mode = modeForm
+	return m.form.Init()
+}
+
+func (m *model) closeForm() {
+	m.form = nil
+	m.mode = modeList
+}
+
+func (m *model) openAddForm() tea.Cmd {
+	fields := []form.Field{
+		{Label: "Title"},
+		{Label: "Description"},
+		{Label: "Category"},
+		{Label: "Due", Placeholder: "e.g. 2d, fri, nov 3, tomorrow 9am (default: today)"},
+		{Label: "Tags", Placeholder: "comma separated"},
+		{Label: "Estimate", Placeholder: "e.g. 2h, 3pt"},
+	}
+	return m.openForm("New task", fields, func(values []string) error {
+		if values[0] == "" {
+			return fmt.Errorf("title is required")
+		}
+		due, err := dates.ParseDue(values[3])
+		if err != nil {
+			return fmt.Errorf("invalid due date: %w", err)
+		}
+		est, err := tasks.ParseEstimate(values[5])
+		if err != nil {
+			return err
+		}
+		task := types.Task{
+			Title:       values[0],
+			Description: values[1],
+			Category:    values[2],
+			Tags:        splitTags(values[4]),
+			Estimate:    est,
+		}
+		task.SetDue(due.Time, due.HasTime)
+		err = m.write(func() error {
+			var err error
+			task, err = tasks.CreateTask(task)
+			return err
+		})
+		m.setResult(err, "Added: "+task.Title)
+		return err
+	})
+}
+
+func (m *model) openEditForm() tea.Cmd {
+	t := m.selectedActiveTask()
+	if t == nil {
+		return nil
+	}
+	original := *t
+	originalDue := original.DueString(formDateFormat)
+	fields := []form.Field{
+		{Label: "Title", Value: original.Title},
+		{Label: "Description", Value: original.Description},
+		{Label: "Category", Value: original.Category},
+		{Label: "Due", Value: originalDue},
+		{Label: "Priority", Value: strconv.Itoa(original.Priority)},
+		{Label: "Tags", Value: strings.Join(original.Tags, ", ")},
+		{Label: "Estimate", Value: estimateString(original.Estimate), Placeholder: "e.g. 2h, 3pt"},
+	}
+	return m.openForm("Edit task", fields, func(values []string) error {
+		if values[0] == "" {
+			return fmt.Errorf("title is required")
+		}
+		updated := original
+		updated.Title = values[0]
+		updated.Description = values[1]
+		updated.Category = values[2]
+		// only parse the due date if it was changed, so the time of day isn't lost
+		if values[3] != originalDue {
+			due, err := dates.ParseDue(values[3])
+			if err != nil {
+				return fmt.Errorf("invalid due date: %w", err)
+			}
+			updated.SetDue(due.Time, due.HasTime)
+		}
+		priority, err := strconv.Atoi(values[4])
+		if err != nil {
+			return fmt.Errorf("invalid priority: %s", values[4])
+		}
+		updated.Priority = priority
+		updated.Tags = splitTags(values[5])
+		updated.Estimate, err = tasks.ParseEstimate(values[6])
+		if err != nil {
+			return err
+		}
+		err = m.write(func() error { return tasks.UpdateTask(updated) })
+		m.setResult(err, "Updated: "+updated.Title)
+		return err
+	})
+}
+
+func (m *model) openNoteForm() tea.Cmd {
+	t := m.selectedActiveTask()
+	if t == nil {
+		return nil
+	}
+	id := t.ID
+	return m.openForm("Add note to "+t.Title, []form.Field{{Label: "Note"}}, func(values []string) error {
+		if values[0] == "" {
+			return fmt.Errorf("note is empty")
+		}
+		err := m.write(func() error {
+			_, err := tasks.AddNote(id, values[0], "")
+			return err
+		})
+		m.setResult(err, "Note added.")
+		return err
+	})
+}
+
+// openEditorNote suspends the TUI and opens the user's editor to write a note
+func (m *model) openEditorNote() tea.Cmd {
+	t := m.selectedActiveTask()
+	if t == nil {
+		return nil
+	}
+	temp, err := os.CreateTemp("", "note-*.txt")
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return nil
+	}
+	temp.Close()
+	id := t.ID
+	return tea.ExecProcess(util.EditorExecCmd(temp.Name()), func(err error) tea.Msg {
+		return editorFinishedMsg{taskID: id, path: temp.Name(), err: err}
+	})
+}
+
+func (m *model) finishEditorNote(msg editorFinishedMsg) {
+	defer os.Remove(msg.path)
+	if msg.err != nil {
+		m.status = "Error running editor: " + msg.err.Error()
+		return
+	}
+	content, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	note := strings.TrimSpace(string(content))
+	if note == "" {
+		m.status = "No note was entered."
+		return
+	}
+	err = m.write(func() error {
+		_, err := tasks.AddNote(msg.taskID, note, "")
+		return err
+	})
+	m.setResult(err, "Note added.")
+}
+
+func (m *model) onSelectTask(item list.Item) {
+	t, ok := item.(taskItem)
+	if !ok {
+		return
+	}
+	if len(t.task.Notes) == 0 {
+		m.status = "This task has no notes."
+		return
+	}
+	m.noteViewer.SetNoteContent(t.task.Title, formatNotes(t.task))
+	m.mode = modeNotes
+}
+
+func (m *model) closeNotes() {
+	m.mode = modeList
+}
+
+// formatNotes joins all of a task's notes into one text, oldest first
+func formatNotes(t types.Task) string {
+	parts := make([]string, len(t.Notes))
+	for i, note := range t.Notes {
+		// a heading per note, since the note viewer renders them as markdown
+		parts[i] = fmt.Sprintf("## %s\n\n%s", note.Name(), note.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func estimateString(e *types.Estimate) string {
+	if e == nil {
+		return ""
+	}
+	return e.String()
+}
+
+func splitTags(s string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (m *model) listWidth() int {
+	return m.width * 55 / 100
+}
+
+func (m *model) detailWidth() int {
+	return m.width - m.listWidth() - 2
+}
+
+func (m *model) View() string {
+	if m.mode == modeNotes {
+		return m.noteViewer.View()
+	}
+
+	var right string
+	if m.mode == modeForm {
+		right = detailStyle.Width(m.detailWidth()).Render(m.form.View())
+	} else {
+		right = m.detailView()
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), right)
+
+	footer := m.status
+	if m.mode == modeConfirm {
+		footer = m.confirmPrompt
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, body, statusStyle.Render(footer))
+}
+
+func (m *model) detailView() string {
+	t := m.selectedTask()
+	width := m.detailWidth()
+	if t == nil {
+		return detailStyle.Width(width).Render("No tasks. Press a to add one.")
+	}
+
+	row := func(label, value string) string {
+		return lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(label), value)
+	}
+	lines := []string{
+		titleStyle.Render(t.Title),
+		"",
+		row("ID", t.ID),
+		row("Status", constants.TaskStatusDisplay[t.Status]),
+		row("Due", t.DueString("Mon Jan 2 2006")),
+		row("Priority", strconv.Itoa(t.Priority)),
+	}
+	if t.Category != "" {
+		lines = append(lines, row("Category", t.Category))
+	}
+	if len(t.Tags) > 0 {
+		lines = append(lines, row("Tags", strings.Join(t.Tags, ", ")))
+	}
+	if t.Estimate != nil {
+		lines = append(lines, row("Estimate", t.Estimate.String()))
+	}
+	lines = append(lines, row("Updated", t.LastUpdate.Format("Jan 2 15:04")))
+	if t.Description != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Width(width-4).Render(t.Description))
+	}
+	if len(t.Notes) > 0 {
+		latest := t.Notes[len(t.Notes)-1]
+		lines = append(lines, "",
+			labelStyle.Width(0).Render(fmt.Sprintf("%d note(s), latest %s:", len(t.Notes), latest.Name())),
+			lipgloss.NewStyle().Width(width-4).Render(util.Truncate(latest.Content, (width-4)*3)),
+		)
+	}
+	return detailStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,320 @@
+package focus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/storage"
+	"github.com/webbben/task/internal/tasks"
+	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/util"
+)
+
+// how long to wait for the database lock when reading or writing
+const lockTimeout = 2 * time.Second
+
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true)
+	phaseStyle  = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	workStyle   = phaseStyle.Background(lipgloss.Color("1")).Foreground(lipgloss.Color("15"))
+	breakStyle  = phaseStyle.Background(lipgloss.Color("2")).Foreground(lipgloss.Color("0"))
+	clockStyle  = lipgloss.NewStyle().Bold(true).Margin(1, 0)
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+// Options are the intervals of a focus session
+type Options struct {
+	Work      time.Duration
+	Break     time.Duration
+	LongBreak time.Duration
+	// LongBreakEvery is the number of work intervals between long breaks
+	LongBreakEvery int
+}
+
+type phase int
+
+const (
+	phaseWork phase = iota
+	phaseBreak
+	phaseLongBreak
+	// phaseDone is the end of the session, where the user is asked if the task is complete
+	phaseDone
+)
+
+var phaseNames = map[phase]string{
+	phaseWork:      "WORK",
+	phaseBreak:     "BREAK",
+	phaseLongBreak: "LONG BREAK",
+}
+
+type tickMsg time.Time
+
+type model struct {
+	workspace string
+	task      types.Task
+	opts      Options
+
+	phase   phase
+	length  time.Duration // length of the current phase
+	elapsed time.Duration // time counted in the current phase before the current segment
+	// segmentStart is when the clock was last started, or zero while it's paused.
+	// work segments are logged as time entries when the clock is paused or the phase ends.
+	segmentStart time.Time
+
+	pomodoros int
+	worked    time.Duration
+
+	progress      progress.Model
+	status        string
+	width, height int
+}
+
+// Run launches a focus session for the given task. Completed work intervals are logged on the task as time entries,
+// and a note summarizing the session is added at the end.
+func Run(workspace, taskID string, opts Options) error {
+	m := &model{
+		workspace: workspace,
+		opts:      opts,
+		progress:  progress.New(progress.WithDefaultGradient(), progress.WithoutPercentage()),
+	}
+
+	// a timer started with "task start" would count the same time twice
+	var stopped types.Task
+	err := m.write(func() error {
+		task, err := tasks.GetTask(taskID)
+		if err != nil {
+			return err
+		}
+		if task.Status == constants.TaskStatus.Pending {
+			task.Status = constants.TaskStatus.InProgress
+			if err := tasks.UpdateTask(*task); err != nil {
+				return err
+			}
+		}
+		m.task = *task
+		stopped, _, err = tasks.StopTimer()
+		if errors.Is(err, tasks.ErrNoTimer) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if stopped.ID != "" {
+		m.status = fmt.Sprintf("Stopped the running timer for %s.", stopped.Title)
+	}
+	m.startPhase(phaseWork, true)
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("error occurred while running focus session: %w", err)
+	}
+	return nil
+}
+
+func (m *model) write(fn func() error) error {
+	return storage.WithWorkspace(m.workspace, storage.OpenOptions{Timeout: lockTimeout}, fn)
+}
+
+func (m *model) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m *model) startPhase(p phase, running bool) {
+	m.phase = p
+	m.elapsed = 0
+	m.segmentStart = time.Time{}
+	if running {
+		m.segmentStart = time.Now()
+	}
+	switch p {
+	case phaseWork:
+		m.length = m.opts.Work
+	case phaseBreak:
+		m.length = m.opts.Break
+	case phaseLongBreak:
+		m.length = m.opts.LongBreak
+	}
+}
+
+func (m *model) running() bool {
+	return !m.segmentStart.IsZero()
+}
+
+func (m *model) phaseElapsed() time.Duration {
+	if m.running() {
+		return m.elapsed + time.Since(m.segmentStart)
+	}
+	return m.elapsed
+}
+
+// pause stops the clock, logging the work done since it was started
+func (m *model) pause() {
+	if !m.running() {
+		return
+	}
+	now := time.Now()
+	m.elapsed += now.Sub(m.segmentStart)
+	if m.phase == phaseWork {
+		m.logWork(types.TimeEntry{Start: m.segmentStart, End: now})
+	}
+	m.segmentStart = time.Time{}
+}
+
+func (m *model) logWork(entry types.TimeEntry) {
+	// a few seconds of work isn't worth an entry, e.g. pausing right after starting
+	if entry.End.Sub(entry.Start) < time.Second {
+		return
+	}
+	id := m.task.ID
+	if err := m.write(func() error { return tasks.LogTime(id, entry) }); err != nil {
+		m.status = "Error logging time: " + err.Error()
+		return
+	}
+	m.worked += entry.End.Sub(entry.Start)
+}
+
+// finishPhase moves on to the next phase. Breaks start by themselves, but work waits for the user so breaks can run over.
+func (m *model) finishPhase(completed bool) {
+	m.pause()
+	if m.phase != phaseWork {
+		m.startPhase(phaseWork, false)
+		m.status = "Break's over. Press space to start working."
+		return
+	}
+	if completed {
+		m.pomodoros++
+		m.status = fmt.Sprintf("Pomodoro %d done, take a break.", m.pomodoros)
+	}
+	if m.opts.LongBreakEvery > 0 && completed && m.pomodoros%m.opts.LongBreakEvery == 0 {
+		m.startPhase(phaseLongBreak, true)
+	} else {
+		m.startPhase(phaseBreak, true)
+	}
+}
+
+// endSession logs any work in progress and adds a note summarizing the session
+func (m *model) endSession() {
+	if m.phase == phaseDone {
+		return
+	}
+	m.pause()
+	m.phase = phaseDone
+	m.status = ""
+	if m.worked < time.Minute {
+		return
+	}
+	note := fmt.Sprintf("Focus session: %d pomodoro(s), %s worked.", m.pomodoros, util.FormatDuration(m.worked))
+	id := m.task.ID
+	err := m.write(func() error {
+		_, err := tasks.AddNote(id, note, "")
+		return err
+	})
+	if err != nil {
+		m.status = "Error adding note: " + err.Error()
+	}
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.progress.Width = min(60, max(10, msg.Width-4))
+	case tickMsg:
+		if m.phase != phaseDone && m.running() && m.phaseElapsed() >= m.length {
+			m.finishPhase(true)
+		}
+		return m, tick()
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.endSession()
+			return m, tea.Quit
+		}
+		if m.phase == phaseDone {
+			return m, m.handleDoneKey(msg)
+		}
+		switch msg.String() {
+		case " ", "p":
+			if m.running() {
+				m.pause()
+				m.status = "Paused."
+			} else {
+				m.segmentStart = time.Now()
+				m.status = ""
+			}
+		case "s":
+			// skipping work still logs the time spent, but doesn't count as a pomodoro
+			m.finishPhase(false)
+		case "q", "esc":
+			m.endSession()
+		}
+	}
+	return m, nil
+}
+
+func (m *model) handleDoneKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		id := m.task.ID
+		if err := m.write(func() error { return tasks.CompleteTask(id) }); err != nil {
+			m.status = "Error completing task: " + err.Error()
+			return nil
+		}
+		return tea.Quit
+	case "n", "N", "q", "esc":
+		return tea.Quit
+	}
+	return nil
+}
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return "\n Initializing..."
+	}
+
+	lines := []string{titleStyle.Render(m.task.Title), ""}
+	if m.phase == phaseDone {
+		lines = append(lines,
+			fmt.Sprintf("Session over: %d pomodoro(s), %s worked.", m.pomodoros, util.FormatDuration(m.worked)),
+			"",
+			"Is the task complete? (y/n)",
+		)
+	} else {
+		style := breakStyle
+		if m.phase == phaseWork {
+			style = workStyle
+		}
+		remaining := max(0, m.length-m.phaseElapsed())
+		clock := fmt.Sprintf("%02d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+		if !m.running() {
+			clock += dimStyle.Render(" (paused)")
+		}
+		lines = append(lines,
+			style.Render(phaseNames[m.phase]),
+			clockStyle.Render(clock),
+			m.progress.ViewAs(float64(m.phaseElapsed())/float64(m.length)),
+			"",
+			fmt.Sprintf("%s %d · %s worked", strings.Repeat("●", min(m.pomodoros, 12)), m.pomodoros, util.FormatDuration(m.worked)),
+			"",
+			dimStyle.Render("space: pause/resume • s: skip • q: end session"),
+		)
+	}
+	lines = append(lines, "", statusStyle.Render(m.status))
+
+	body := lipgloss.JoinVertical(lipgloss.Center, lines...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, body)
+}
@@ -0,0 +1,83 @@
+package picker
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/types"
+	listcomponent "github.com/webbben/task/internal/ui/components/list-component"
+)
+
+type taskItem struct {
+	task types.Task
+}
+
+func (item taskItem) FilterValue() string {
+	return item.task.Title
+}
+
+func (item taskItem) Title() string {
+	return item.task.Title
+}
+
+func (item taskItem) Description() string {
+	return fmt.Sprintf("%s · due %s · %s", item.task.ID, item.task.DueString("Jan 2"), constants.TaskStatusDisplay[item.task.Status])
+}
+
+type model struct {
+	list   listcomponent.ListComponentModel
+	picked *types.Task
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && !m.list.Filtering() {
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	if m.picked != nil {
+		return m, tea.Quit
+	}
+	return m, cmd
+}
+
+func (m *model) View() string {
+	if m.picked != nil {
+		// don't leave the list on the screen once a task is picked
+		return ""
+	}
+	return m.list.View()
+}
+
+func (m *model) onSelect(item list.Item) {
+	t := item.(taskItem).task
+	m.picked = &t
+}
+
+// Pick lets the user choose one of the tasks from a list. ok is false if the list was closed without choosing one.
+func Pick(title string, tasks []types.Task) (picked types.Task, ok bool, err error) {
+	items := make([]list.Item, len(tasks))
+	for i, t := range tasks {
+		items[i] = taskItem{task: t}
+	}
+	m := &model{}
+	m.list = listcomponent.New(items, title, 0, 0, m.onSelect)
+	m.list.SetStatusBarItemName("task", "tasks")
+
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		return types.Task{}, false, fmt.Errorf("failed to run task picker: %w", err)
+	}
+	if m.picked == nil {
+		return types.Task{}, false, nil
+	}
+	return *m.picked, true, nil
+}
@@ -2,132 +2,510 @@ package taskui
 
 import (
 	"fmt"
-	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/webbben/task/internal/constants"
+	"github.com/webbben/task/internal/storage"
 	"github.com/webbben/task/internal/tasks"
 	"github.com/webbben/task/internal/types"
+	"github.com/webbben/task/internal/ui/components/form"
 	listcomponent "github.com/webbben/task/internal/ui/components/list-component"
 	noteviewer "github.com/webbben/task/internal/ui/components/note-viewer"
+	"github.com/webbben/task/internal/util"
 )
 
+// how long to wait for the database lock when reading or writing
+const lockTimeout = 2 * time.Second
+
+var (
+	headerStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("8")).
+			Padding(0, 1).
+			Margin(1, 2, 0)
+	labelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Width(10)
+	titleStyle  = lipgloss.NewStyle().Bold(true)
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Padding(0, 2)
+	formStyle   = lipgloss.NewStyle().Margin(1, 2)
+)
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeForm
+	modeNote
+	modeConfirm
+)
+
+var keys = []key.Binding{
+	key.NewBinding(key.WithKeys("a"), key.WithHelp("a/A", "add/editor note")),
+	key.NewBinding(key.WithKeys("e"), key.WithHelp("e/E", "edit/editor edit")),
+	key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+	key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "view")),
+	key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+}
+
+type editorFinishedMsg struct {
+	noteID string // empty for a new note
+	path   string
+	err    error
+}
+
 type model struct {
-	content        *types.Task
-	noteViewer     *noteviewer.NoteViewerModel
-	noteViewerOpen bool
-	noteList       listcomponent.ListComponentModel
+	workspace  string
+	content    *types.Task
+	noteViewer *noteviewer.NoteViewerModel
+	noteList   listcomponent.ListComponentModel
+	form       *form.FormModel
+	mode       mode
+
+	confirmPrompt string
+	onConfirm     func() error
+
+	status        string
+	width, height int
 }
 
 type noteListItem struct {
-	title   string
-	content string
+	note types.Note
 }
 
 func (item noteListItem) FilterValue() string {
-	return item.title
+	return item.note.Name() + " " + item.note.Content
 }
 
 func (item noteListItem) Title() string {
-	return item.title
+	title := fmt.Sprintf("%s · %s", item.note.ID, item.note.Name())
+	if item.note.Edited.Sub(item.note.Created) >= time.Minute {
+		title += " (edited " + item.note.Edited.Format(types.NoteTimeFormat) + ")"
+	}
+	return title
 }
 
 func (item noteListItem) Description() string {
-	if len(item.content) < 15 {
-		return item.content
-	}
-	return item.content[:15] + "..."
+	// only the first line, since the list shows a single line per item
+	firstLine, _, _ := strings.Cut(item.note.Content, "\n")
+	return util.Truncate(firstLine, 60)
 }
 
 func (m model) Init() tea.Cmd {
 	return nil
 }
 
-func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	var cmds []tea.Cmd
+// reload reads the task from the database and rebuilds the note list, keeping the same note selected if it still exists
+func (m *model) reload() error {
+	id := m.content.ID
+	var task *types.Task
+	err := storage.WithWorkspace(m.workspace, storage.OpenOptions{ReadOnly: true, Timeout: lockTimeout}, func() error {
+		var err error
+		task, err = tasks.GetTask(id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	m.content = task
+	m.updateList()
+	return nil
+}
 
-	// note viewer
-	newNoteViewerModel, cmd := m.noteViewer.Update(msg)
-	m.noteViewer = newNoteViewerModel
-	cmds = append(cmds, cmd)
+// write opens the database for writing for the duration of fn, and reloads the task afterwards
+func (m *model) write(fn func() error) error {
+	err := storage.WithWorkspace(m.workspace, storage.OpenOptions{Timeout: lockTimeout}, fn)
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
 
-	// note list
-	newNoteListModel, cmd := m.noteList.Update(msg)
-	m.noteList = newNoteListModel
-	cmds = append(cmds, cmd)
+// updateList fills the note list with the task's notes, newest first
+func (m *model) updateList() {
+	selected := ""
+	if item, ok := m.noteList.SelectedItem().(noteListItem); ok {
+		selected = item.note.ID
+	}
+	notes := m.content.Notes
+	items := make([]list.Item, len(notes))
+	index := 0
+	for i, note := range notes {
+		pos := len(notes) - 1 - i
+		items[pos] = noteListItem{note: note}
+		if note.ID == selected {
+			index = pos
+		}
+	}
+	m.noteList.SetItems(items)
+	m.noteList.Select(index)
+	m.noteList.SetTitle(fmt.Sprintf("Notes (%d)", len(notes)))
+}
 
+func (m *model) selectedNote() (noteListItem, bool) {
+	item, ok := m.noteList.SelectedItem().(noteListItem)
+	return item, ok
+}
+
+func (m *model) setResult(err error, success string) {
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.status = success
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		if m.form != nil {
+			m.form.SetWidth(m.width - formStyle.GetHorizontalFrameSize())
+		}
+		// the note viewer always uses the full window
+		var cmd tea.Cmd
+		m.noteViewer, cmd = m.noteViewer.Update(msg)
+		return m, cmd
+	case editorFinishedMsg:
+		m.finishEditorNote(msg)
+		return m, nil
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" || msg.String() == "esc" {
+		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
 	}
 
-	return m, tea.Batch(cmds...)
+	switch m.mode {
+	case modeNote:
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+			m.onCloseNote()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.noteViewer, cmd = m.noteViewer.Update(msg)
+		return m, cmd
+	case modeForm:
+		var cmd tea.Cmd
+		m.form, cmd = m.form.Update(msg)
+		return m, cmd
+	case modeConfirm:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "y", "Y":
+				m.setResult(m.onConfirm(), "Note deleted.")
+			default:
+				m.status = "Cancelled."
+			}
+			m.mode = modeList
+		}
+		return m, nil
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && !m.noteList.Filtering() {
+		if handled, cmd := m.handleKey(msg); handled {
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	m.noteList, cmd = m.noteList.Update(msg)
+	return m, cmd
+}
+
+// handleKey handles the note actions. returns false if the key should be passed on to the list.
+func (m *model) handleKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	m.status = ""
+	switch msg.String() {
+	case "q", "esc":
+		return true, tea.Quit
+	case "a":
+		return true, m.openNoteForm("", "")
+	case "A":
+		return true, m.openEditorNote("", "")
+	case "e", "E":
+		item, ok := m.selectedNote()
+		if !ok {
+			return true, nil
+		}
+		// the form only has single line inputs, so multi-line notes are always edited in the editor
+		if msg.String() == "E" || strings.Contains(item.note.Content, "\n") {
+			return true, m.openEditorNote(item.note.ID, item.note.Content)
+		}
+		return true, m.openNoteForm(item.note.ID, item.note.Content)
+	case "d":
+		if item, ok := m.selectedNote(); ok {
+			id, noteID := m.content.ID, item.note.ID
+			m.confirm(fmt.Sprintf("Delete note %s (%s)? (y/n)", noteID, item.note.Name()), func() error {
+				return m.write(func() error {
+					_, err := tasks.DeleteNote(id, noteID)
+					return err
+				})
+			})
+		}
+		return true, nil
+	case "r":
+		m.setResult(m.reload(), "Refreshed.")
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *model) confirm(prompt string, onConfirm func() error) {
+	m.mode = modeConfirm
+	m.confirmPrompt = prompt
+	m.onConfirm = onConfirm
+}
+
+// openNoteForm opens a form to add a note, or edit the note with the given ID
+func (m *model) openNoteForm(noteID, content string) tea.Cmd {
+	title := "Add note"
+	if noteID != "" {
+		title = "Edit note " + noteID
+	}
+	m.form = form.New(title, []form.Field{{Label: "Note", Value: content}}, func(values []string) {
+		if err := m.saveNote(noteID, values[0]); err != nil {
+			// keep the form open so the input isn't lost
+			m.status = "Error: " + err.Error()
+			return
+		}
+		m.closeForm()
+	}, m.closeForm)
+	m.form.SetWidth(m.width - formStyle.GetHorizontalFrameSize())
+	m.mode = modeForm
+	return m.form.Init()
+}
+
+func (m *model) closeForm() {
+	m.form = nil
+	m.mode = modeList
+}
+
+// saveNote adds a new note if noteID is empty, otherwise replaces the content of the note
+func (m *model) saveNote(noteID, content string) error {
+	if content == "" {
+		return fmt.Errorf("note is empty")
+	}
+	id := m.content.ID
+	if noteID == "" {
+		err := m.write(func() error {
+			_, err := tasks.AddNote(id, content, "")
+			return err
+		})
+		m.setResult(err, "Note added.")
+		return err
+	}
+	err := m.write(func() error {
+		_, err := tasks.EditNote(id, noteID, content)
+		return err
+	})
+	m.setResult(err, "Note updated.")
+	return err
+}
+
+// openEditorNote suspends the TUI and opens the user's editor to write a new note, or edit the note with the given ID
+func (m *model) openEditorNote(noteID, content string) tea.Cmd {
+	temp, err := os.CreateTemp("", "note-*.txt")
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return nil
+	}
+	_, err = temp.WriteString(content)
+	temp.Close()
+	if err != nil {
+		os.Remove(temp.Name())
+		m.status = "Error: " + err.Error()
+		return nil
+	}
+	return tea.ExecProcess(util.EditorExecCmd(temp.Name()), func(err error) tea.Msg {
+		return editorFinishedMsg{noteID: noteID, path: temp.Name(), err: err}
+	})
+}
+
+func (m *model) finishEditorNote(msg editorFinishedMsg) {
+	defer os.Remove(msg.path)
+	if msg.err != nil {
+		m.status = "Error running editor: " + msg.err.Error()
+		return
+	}
+	content, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	note := strings.TrimSpace(string(content))
+	if note == "" {
+		m.status = "No note was entered."
+		return
+	}
+	if i, err := tasks.FindNote(*m.content, msg.noteID); msg.noteID != "" && err == nil && note == m.content.Notes[i].Content {
+		m.status = "Note unchanged."
+		return
+	}
+	m.saveNote(msg.noteID, note)
+}
+
+// resize fits the note list into the space below the header
+func (m *model) resize() {
+	used := lipgloss.Height(m.headerView()) + 1 // status line
+	m.noteList.SetSize(m.width, max(5, m.height-used))
 }
 
 func (m model) View() string {
-	if m.noteViewerOpen {
+	if m.mode == modeNote {
 		return m.noteViewer.View()
 	}
 
-	return m.noteList.View()
+	var body string
+	if m.mode == modeForm {
+		body = formStyle.Render(m.form.View())
+	} else {
+		body = m.noteList.View()
+	}
+
+	footer := m.status
+	if m.mode == modeConfirm {
+		footer = m.confirmPrompt
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.headerView(), body, statusStyle.Render(footer))
 }
 
-func (m *model) setSelectedNote(noteTitle string) {
-	noteContent, exists := m.content.Notes[noteTitle]
-	if !exists {
-		log.Println("note not found")
-		return
+// headerView shows all the task's details above the notes
+func (m model) headerView() string {
+	t := m.content
+	// lipgloss widths include the padding, but not the border or margins
+	width := max(20, m.width-headerStyle.GetHorizontalMargins()-headerStyle.GetHorizontalBorderSize())
+	inner := width - headerStyle.GetHorizontalPadding()
+
+	row := func(label, value string) string {
+		return lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(label), value)
+	}
+	status, ok := constants.TaskStatusDisplay[t.Status]
+	if !ok {
+		status = strconv.Itoa(t.Status)
+	}
+	category := t.Category
+	if category == "" {
+		category = "-"
+	}
+	tags := strings.Join(t.Tags, ", ")
+	if tags == "" {
+		tags = "-"
+	}
+	estimate := "-"
+	if t.Estimate != nil {
+		estimate = t.Estimate.String()
+	}
+	col := func(rows ...string) string {
+		return lipgloss.NewStyle().Width(inner / 3).Render(strings.Join(rows, "\n"))
+	}
+	lines := []string{
+		titleStyle.Render(t.Title),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			col(
+				row("ID", t.ID),
+				row("Status", status),
+				row("Priority", strconv.Itoa(t.Priority)),
+			),
+			col(
+				row("Due", t.DueString("Mon Jan 2 2006")),
+				row("Category", category),
+				row("Tags", tags),
+			),
+			col(
+				row("Estimate", estimate),
+				row("Spent", util.FormatDuration(t.TimeSpent())),
+			),
+		),
+		row("Updated", fmt.Sprintf("%s (%s ago)", t.LastUpdate.Format("Mon Jan 2 2006 15:04"), timeSince(t.LastUpdate))),
+	}
+	if t.Description != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Width(inner).Render(t.Description))
+	}
+	if len(t.Attachments) > 0 {
+		// numbered the same as "task open"
+		lines = append(lines, "", labelStyle.Width(0).Render("Attachments"))
+		for i, a := range t.Attachments {
+			detail := a.URL
+			if !a.IsURL() {
+				detail = util.FormatSize(a.Size)
+				if a.FileName != a.Name {
+					detail = a.FileName + ", " + detail
+				}
+			}
+			if detail == a.Name {
+				detail = ""
+			} else {
+				detail = labelStyle.Width(0).Render(" " + detail)
+			}
+			lines = append(lines, util.Truncate(fmt.Sprintf("%d. %s", i+1, a.Name), inner-lipgloss.Width(detail))+detail)
+		}
 	}
-	if noteContent == "" {
-		log.Println("selected note is empty?")
+	return headerStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func timeSince(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+func (m *model) setSelectedNote(noteID string) {
+	i, err := tasks.FindNote(*m.content, noteID)
+	if err != nil {
+		m.status = err.Error()
 		return
 	}
-	m.noteViewer.SetNoteContent(noteTitle, noteContent)
-	m.noteViewerOpen = true
+	note := m.content.Notes[i]
+	m.noteViewer.SetNoteContent(note.Name(), note.Content)
+	m.mode = modeNote
 }
 
 func (m *model) onSelectNote(item list.Item) {
 	noteItem, ok := item.(noteListItem)
 	if !ok {
-		log.Println("note list item can't be converted to list.Item")
 		return
 	}
-	m.setSelectedNote(noteItem.title)
+	m.setSelectedNote(noteItem.note.ID)
 }
 
 func (m *model) onCloseNote() {
-	m.noteViewerOpen = false
+	m.mode = modeList
 }
 
-func RunUI(taskID string) error {
-	task, err := tasks.GetTask(taskID)
-	if err != nil {
-		return fmt.Errorf("failed to run task UI: %d", err)
-	}
-
+// RunUI launches the task detail TUI for the given task in the given workspace.
+// the database is only opened while reading or saving, so other task commands can run alongside it.
+func RunUI(workspace, taskID string) error {
 	m := &model{
-		content: task,
+		workspace: workspace,
+		content:   &types.Task{ID: taskID},
 	}
+	m.noteList = listcomponent.New([]list.Item{}, "Notes", 0, 0, m.onSelectNote)
+	m.noteList.SetStatusBarItemName("note", "notes")
+	m.noteList.SetAdditionalHelpKeys(func() []key.Binding { return keys })
+	m.noteViewer = noteviewer.New("", "", m.onCloseNote)
 
-	// set up note list
-	noteList := make([]list.Item, 0)
-	for title, text := range task.Notes {
-		noteList = append(noteList, noteListItem{
-			title:   title,
-			content: text,
-		})
+	if err := m.reload(); err != nil {
+		return fmt.Errorf("failed to run task UI: %w", err)
 	}
-	m.noteList = listcomponent.New(noteList, task.Title, 0, 0, m.onSelectNote)
-
-	m.noteViewer = noteviewer.New("", "", m.onCloseNote)
 
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 	if _, err := p.Run(); err != nil {
-		return fmt.Errorf("error occurred while viewing task: %d", err)
+		return fmt.Errorf("error occurred while viewing task: %w", err)
 	}
 	return nil
 }
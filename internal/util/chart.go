@@ -0,0 +1,36 @@
+package util
+
+import (
+	"strings"
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the values as a line of block characters, scaled from the smallest to the largest value
+func Sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = (v - lo) * (len(sparkBlocks) - 1) / (hi - lo)
+		}
+		sb.WriteRune(sparkBlocks[i])
+	}
+	return sb.String()
+}
+
+// Bar draws a horizontal bar for n, scaled so that maxValue fills the width
+func Bar(n, maxValue, width int) string {
+	if n <= 0 || maxValue <= 0 {
+		return ""
+	}
+	// any value above zero gets at least a sliver, so it isn't mistaken for zero
+	return strings.Repeat("█", max(n*width/maxValue, 1))
+}
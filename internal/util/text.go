@@ -0,0 +1,107 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// matches ANSI escape sequences: CSI sequences like colors (\x1b[31m) and OSC sequences like hyperlinks (\x1b]8;;url\x07)
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+const ellipsis = "..."
+
+// Width returns the number of terminal cells the string takes up when displayed.
+// ANSI escape sequences take up no space, and wide characters (e.g. CJK, emoji) take up two cells.
+func Width(s string) int {
+	return uniseg.StringWidth(StripAnsi(s))
+}
+
+// Truncate shortens the string to fit in the given display width, ending it with "..." if anything was cut off.
+//
+// ANSI escape sequences are kept (so colors are still reset at the end), and characters are never split in the middle.
+func Truncate(s string, maxWidth int) string {
+	if Width(s) <= maxWidth {
+		return s
+	}
+	// too short to fit an ellipsis
+	if maxWidth <= len(ellipsis) {
+		return Cut(s, maxWidth)
+	}
+	return cutWidth(s, maxWidth-len(ellipsis), ellipsis)
+}
+
+// Cut cuts off the string at the given display width, without adding an ellipsis
+func Cut(s string, maxWidth int) string {
+	if Width(s) <= maxWidth {
+		return s
+	}
+	return cutWidth(s, maxWidth, "")
+}
+
+// cutWidth keeps as many whole grapheme clusters as fit in the given width, followed by the suffix.
+// all ANSI escape sequences are kept, even those after the cut, so that any styling is closed properly.
+func cutWidth(s string, maxWidth int, suffix string) string {
+	var out strings.Builder
+	width := 0
+	cut := false
+	for _, part := range splitAnsi(s) {
+		if part.escape {
+			out.WriteString(part.text)
+			continue
+		}
+		if cut {
+			continue
+		}
+		state := -1
+		rest := part.text
+		for len(rest) > 0 {
+			var cluster string
+			var w int
+			cluster, rest, w, state = uniseg.FirstGraphemeClusterInString(rest, state)
+			if width+w > maxWidth {
+				out.WriteString(suffix)
+				cut = true
+				break
+			}
+			out.WriteString(cluster)
+			width += w
+		}
+	}
+	return out.String()
+}
+
+type textPart struct {
+	text   string
+	escape bool
+}
+
+// splitAnsi splits the string into runs of plain text and ANSI escape sequences
+func splitAnsi(s string) []textPart {
+	parts := make([]textPart, 0)
+	last := 0
+	for _, loc := range ansiEscape.FindAllStringIndex(s, -1) {
+		if loc[0] > last {
+			parts = append(parts, textPart{text: s[last:loc[0]]})
+		}
+		parts = append(parts, textPart{text: s[loc[0]:loc[1]], escape: true})
+		last = loc[1]
+	}
+	if last < len(s) {
+		parts = append(parts, textPart{text: s[last:]})
+	}
+	return parts
+}
+
+// PadRight adds spaces to the end of the string until it fills the given display width
+func PadRight(s string, width int) string {
+	if padding := width - Width(s); padding > 0 {
+		s += strings.Repeat(" ", padding)
+	}
+	return s
+}
+
+func StripAnsi(str string) string {
+	return ansiEscape.ReplaceAllString(str, "")
+}
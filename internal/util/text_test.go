@@ -0,0 +1,78 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/webbben/task/internal/testutil"
+)
+
+// samples are strings whose display width differs from their length in bytes or runes
+var samples = []struct {
+	name  string
+	text  string
+	width int
+}{
+	{"ascii", "deploy the fix", 14},
+	{"cjk", "日本語のタスク", 14},
+	{"cjk mixed", "fix 日本 bug", 12},
+	{"emoji zwj", "👩‍💻 pair on 👨‍👩‍👧 demo", 18},
+	{"flag", "🇯🇵 trip", 7},
+	{"combining marks", "café déjà vu", 12},
+	{"ansi color", "\x1b[31mlate\x1b[0m review", 11},
+	{"ansi wide", "\x1b[1;32m完了\x1b[0m done", 9},
+	{"hyperlink", "\x1b]8;;https://example.com\x07link\x1b]8;;\x07 text", 9},
+}
+
+var cutWidths = []int{0, 1, 3, 4, 5, 8, 11, 20}
+
+func TestWidth(t *testing.T) {
+	for _, s := range samples {
+		if got := Width(s.text); got != s.width {
+			t.Errorf("Width(%q) [%s] = %d, want %d", s.text, s.name, got, s.width)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	testutil.CheckGolden(t, "truncate", renderCuts(t, Truncate))
+}
+
+func TestCut(t *testing.T) {
+	testutil.CheckGolden(t, "cut", renderCuts(t, Cut))
+}
+
+func TestPadRight(t *testing.T) {
+	for _, s := range samples {
+		for _, w := range []int{0, s.width - 1, s.width, s.width + 3} {
+			got := PadRight(s.text, w)
+			if want := max(w, s.width); Width(got) != want {
+				t.Errorf("Width(PadRight(%q, %d)) = %d, want %d", s.text, w, Width(got), want)
+			}
+			if !strings.HasPrefix(got, s.text) {
+				t.Errorf("PadRight(%q, %d) = %q, which changed the text", s.text, w, got)
+			}
+		}
+	}
+}
+
+// renderCuts cuts every sample at each width, padded and boxed with | so the golden file shows the alignment
+func renderCuts(t *testing.T, cut func(string, int) string) string {
+	var sb strings.Builder
+	for _, s := range samples {
+		fmt.Fprintf(&sb, "# %s\n", s.name)
+		for _, w := range cutWidths {
+			got := cut(s.text, w)
+			if Width(got) > w {
+				t.Errorf("cutting %q [%s] to %d gave %q, which is %d wide", s.text, s.name, w, got, Width(got))
+			}
+			if strings.Count(got, "\x1b") != strings.Count(s.text, "\x1b") {
+				t.Errorf("cutting %q [%s] to %d gave %q, which lost escape sequences", s.text, s.name, w, got)
+			}
+			fmt.Fprintf(&sb, "%2d |%s|\n", w, PadRight(got, w))
+		}
+	}
+	// show escape sequences as text, so the file can be read and diffed
+	return strings.NewReplacer("\x1b", `\e`, "\x07", `\a`).Replace(sb.String())
+}
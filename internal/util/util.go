@@ -6,80 +6,122 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"regexp"
+	"runtime"
 	"strings"
 	"time"
-)
-
-var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
-
-func Truncate(s string, maxLength int) string {
-	ansiCodes := extractANSI(s)
-
-	// ansi codes can make the string appear longer than it actually is visibly
-	// so handle ansi encoded strings separately
-	if len(ansiCodes) > 0 {
-		stripped := StripAnsi(s)
-		if len(stripped) > maxLength {
-			stripped = stripped[:maxLength-3] + "..."
-		}
-		return ansiCodes[0] + stripped + ansiCodes[1]
-	}
 
-	// for normal strings, just check it as it is
-	if len(s) > maxLength {
-		return s[:maxLength-3] + "..."
-	}
-	return s
-}
-
-func StripAnsi(str string) string {
-	return ansiEscape.ReplaceAllString(str, "")
-}
+	"github.com/webbben/task/internal/config"
+)
 
-func extractANSI(input string) []string {
-	// Find all ANSI sequences in the input string
-	return ansiEscape.FindAllString(input, -1)
-}
+// stdin is shared by every prompt, since a reader of its own would buffer answers meant for later prompts
+var stdin = bufio.NewReader(os.Stdin)
 
 func Confirm(prompt string) bool {
 	fmt.Print(prompt + " [Y/N]")
 
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
+	input, _ := stdin.ReadString('\n')
 	input = strings.ToLower(strings.TrimSpace(input))
 
 	return input == "y" || input == "yes"
 }
 
 func OpenEditor() string {
-	// create temp file
+	content, err := EditText("")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return content
+}
+
+// EditText opens the given text in the user's editor, and returns the edited text once the editor is closed
+func EditText(text string) (string, error) {
 	temp, err := os.CreateTemp("", "note-*.txt")
 	if err != nil {
-		log.Fatal("Failed to create temp file:", err)
+		return "", fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer os.Remove(temp.Name())
+	_, err = temp.WriteString(text)
+	temp.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
 
-	editor := os.Getenv("EDITOR")
+	if err := EditFile(temp.Name()); err != nil {
+		return "", fmt.Errorf("failed to run editor: %w", err)
+	}
+	content, err := os.ReadFile(temp.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read temp file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// EditorCommand returns the command used to edit text: the configured editor, then $EDITOR, then vi
+func EditorCommand() []string {
+	editor := config.Get().Editor
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
 	if editor == "" {
 		editor = "vi"
 	}
+	// the editor may include arguments, e.g. "code --wait"
+	return strings.Fields(editor)
+}
+
+// EditorExecCmd returns the command to open the given file in the user's editor
+func EditorExecCmd(path string) *exec.Cmd {
+	editor := EditorCommand()
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
 
-	// open editor
-	cmd := exec.Command(editor, temp.Name())
+// EditFile opens the given file in the user's editor and waits for it to close
+func EditFile(path string) error {
+	cmd := EditorExecCmd(path)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
 
-	if err := cmd.Run(); err != nil {
-		log.Fatal("Failed to run editor:", err)
+// Open opens a file or URL with the desktop's default application, without waiting for it to close
+func Open(target string) error {
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
 	}
-	content, err := os.ReadFile(temp.Name())
-	if err != nil {
-		log.Fatal("failed to read temp file:", err)
+	cmd := exec.Command(opener, target)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", opener, err)
 	}
+	// don't leave a zombie process behind while we're still running
+	go cmd.Wait()
+	return nil
+}
 
-	return strings.TrimSpace(string(content))
+// FormatSize formats a number of bytes for display, e.g. 1.5 MB
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatDuration formats a duration in hours and minutes, e.g. 1h05m or 45m
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", h, m)
 }
 
 // RoundDateDown returns the earliest time in the same day as the given time
@@ -87,6 +129,12 @@ func RoundDateDown(date time.Time) time.Time {
 	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 }
 
+// StartOfWeek returns the start of the first day of the week containing the given time
+func StartOfWeek(date time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(date.Weekday()) - int(weekStart) + 7) % 7
+	return RoundDateDown(date.AddDate(0, 0, -offset))
+}
+
 // RoundDateUp returns the latest time in the same day as the given time
 func RoundDateUp(date time.Time) time.Time {
 	return time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 999999999, date.Location())
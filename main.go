@@ -1,16 +1,12 @@
 package main
 
 import (
-	"log"
-
 	"github.com/webbben/task/cmd"
 	"github.com/webbben/task/internal/storage"
 )
 
 func main() {
-	if err := storage.OpenDatabase(storage.TASK_DB); err != nil {
-		log.Fatalf("Failed to open database: %v", err)
-	}
+	// the database is opened by the root command, since how it's opened depends on which command is run
 	defer storage.CloseDatabase()
 
 	cmd.Execute()